@@ -53,6 +53,8 @@ func (i *arrayFlags) Set(value string) error {
 // ReadFlags command-line flags into app config.
 func ReadFlags() config.Config {
 	files := arrayFlags{}
+	sensitive := arrayFlags{}
+	envs := arrayFlags{}
 	result := config.Config{}
 	var h, help, version, crd bool
 	flag.BoolVar(&h, "h", false, "Print help. Example: timonify -h")
@@ -61,13 +63,21 @@ func ReadFlags() config.Config {
 	flag.BoolVar(&result.Verbose, "v", false, "Enable verbose output (print WARN & INFO). Example: timonify -v")
 	flag.BoolVar(&result.VeryVerbose, "vv", false, "Enable very verbose output. Same as verbose but with DEBUG. Example: timonify -vv")
 	flag.BoolVar(&crd, "crd-dir", false, "Enable crd install into 'crds' directory.\nWarning: CRDs placed in 'crds' directory will not be templated by Helm.\nSee https://helm.sh/docs/module_best_practices/custom_resource_definitions/#some-caveats-and-explanations\nExample: timonify -crd-dir")
-	flag.BoolVar(&result.ImagePullSecrets, "image-pull-secrets", false, "Allows the user to use existing secrets as imagePullSecrets in values.yaml")
-	flag.BoolVar(&result.GenerateDefaults, "generate-defaults", false, "Allows the user to add empty placeholders for typical customization options in values.yaml. Currently covers: topology constraints, node selectors, tolerances")
+	flag.BoolVar(&result.ImagePullSecrets, "image-pull-secrets", false, "Allows the user to use existing secrets as imagePullSecrets with module-wide imagePullSecrets in #Config")
+	flag.BoolVar(&result.GenerateDefaults, "generate-defaults", false, "Allows the user to add optional #Config fields for typical customization options. Currently covers: tolerations, affinity, topology spread constraints, node selectors, priority class name")
+	flag.BoolVar(&result.DefaultsInConfig, "defaults-in-config", false, "Put manifest values into #Config as *default | type fields and leave values.cue for user overrides only. Example: timonify -defaults-in-config")
+	flag.BoolVar(&result.HoistSharedValues, "hoist-shared-values", false, "Move container image repositories, env vars and resources equal across workloads to module-wide #config fields. Example: timonify -hoist-shared-values")
+	flag.StringVar(&result.Rules, "rules", "", "Parametrization rules file lifting object fields selected by apiVersion, kind and name and a JSONPath into #Config keys. Example: timonify -rules rules.yaml")
+	flag.StringVar(&result.Overlay, "overlay", "", "CUE file unified with processed objects. Top level fields select objects: \"*\", kind or \"kind/name\", #Config extends the module config. Example: timonify -overlay overlay.cue")
+	flag.Var(&sensitive, "sensitive", "Value name or dot separated #config path to keep out of values.cue as a required @sensitive() field, in addition to Secret values and names like dbPassword, API_TOKEN or apiKey. Can be repeated. Example: timonify -sensitive DB_HOST -sensitive web.app.env.apiUrl")
+	flag.StringVar(&result.ValuesNaming, "values-naming", config.ValuesNamingName, "Naming of object values in #Config: name or kind. kind prefixes values with the object kind to resolve collisions of objects with the same name. Example: timonify -values-naming=kind")
+	flag.StringVar(&result.PodSecurity, "pod-security", config.PodSecurityPrivileged, "Pod Security Standard profile enforced by pod and container securityContext schemas in #Config: privileged, baseline or restricted. Example: timonify -pod-security=restricted")
 	flag.BoolVar(&result.CertManagerAsSubmodule, "cert-manager-as-submodule", false, "Allows the user to add cert-manager as a submodule")
 	flag.StringVar(&result.CertManagerVersion, "cert-manager-version", "v1.12.2", "Allows the user to specify cert-manager submodule version. Only useful with cert-manager-as-submodule.")
 	flag.BoolVar(&result.FilesRecursively, "r", false, "Scan dirs from -f option recursively")
 	flag.BoolVar(&result.OriginalName, "original-name", false, "Use the object's original name instead of adding the module's release name as the common prefix.")
 	flag.Var(&files, "f", "File or directory containing k8s manifests")
+	flag.Var(&envs, "env", "Environment name and file or directory with its k8s manifests, e.g. an overlay of the app. Can be repeated. The module is built from the first environment, values.cue keeps values shared by environments and values-<name>.cue files keep values which differ. Example: timonify -env dev=overlays/dev -env prod=overlays/prod")
 
 	flag.Parse()
 	if h || help {
@@ -88,5 +98,10 @@ func ReadFlags() config.Config {
 		result.Crd = crd
 	}
 	result.Files = files
+	result.Sensitive = sensitive
+	for _, env := range envs {
+		name, path, _ := strings.Cut(env, "=")
+		result.Environments = append(result.Environments, config.Environment{Name: name, Path: path})
+	}
 	return result
 }
@@ -14,7 +14,14 @@ import (
 	"github.com/syndicut/timonify/pkg/decoder"
 	"github.com/syndicut/timonify/pkg/processor"
 	"github.com/syndicut/timonify/pkg/processor/deployment"
+	"github.com/syndicut/timonify/pkg/processor/istio"
+	"github.com/syndicut/timonify/pkg/processor/pod"
+	"github.com/syndicut/timonify/pkg/processor/replicaset"
+	"github.com/syndicut/timonify/pkg/processor/rollout"
+	"github.com/syndicut/timonify/pkg/processor/service"
+	"github.com/syndicut/timonify/pkg/rules"
 	"github.com/syndicut/timonify/pkg/timoni"
+	"github.com/syndicut/timonify/pkg/timonify"
 )
 
 // Start - application entrypoint for processing input to a Helm module.
@@ -33,16 +40,97 @@ func Start(stdin io.Reader, config config.Config) error {
 		logrus.Debug("Received termination, signaling shutdown")
 		cancelFunc()
 	}()
-	appCtx := New(config, timoni.NewOutput())
-	appCtx = appCtx.WithProcessors(
+	if len(config.Environments) != 0 {
+		return startEnvironments(ctx, config)
+	}
+	appCtx, err := newContext(config)
+	if err != nil {
+		return err
+	}
+	if len(config.Files) != 0 {
+		file.Walk(config.Files, config.FilesRecursively, func(filename string, fileReader io.Reader) {
+			objects := decoder.DecodeWithComments(ctx.Done(), fileReader)
+			for obj := range objects {
+				appCtx.Add(obj.Unstructured, filename, obj.Comments)
+			}
+		})
+	} else {
+		objects := decoder.DecodeWithComments(ctx.Done(), stdin)
+		for obj := range objects {
+			appCtx.Add(obj.Unstructured, "", obj.Comments)
+		}
+	}
+
+	if err := appCtx.CreateHelm(ctx.Done()); err != nil {
+		return err
+	}
+	if report := appCtx.Report(); !report.Empty() {
+		return report.Write(os.Stderr)
+	}
+	return nil
+}
+
+// startEnvironments creates module from inputs of every config environment.
+func startEnvironments(ctx context.Context, config config.Config) error {
+	contexts := make([]*appContext, 0, len(config.Environments))
+	for _, env := range config.Environments {
+		appCtx, err := newContext(config)
+		if err != nil {
+			return err
+		}
+		file.Walk([]string{env.Path}, config.FilesRecursively, func(filename string, fileReader io.Reader) {
+			objects := decoder.DecodeWithComments(ctx.Done(), fileReader)
+			for obj := range objects {
+				appCtx.Add(obj.Unstructured, filename, obj.Comments)
+			}
+		})
+		contexts = append(contexts, appCtx)
+	}
+	if err := CreateEnvironments(ctx.Done(), config, contexts); err != nil {
+		return err
+	}
+	report := &timonify.Report{}
+	for _, c := range contexts {
+		report.Merge(c.Report())
+	}
+	if !report.Empty() {
+		return report.Write(os.Stderr)
+	}
+	return nil
+}
+
+// newContext returns app context with processors, parametrization rules and overlay set.
+func newContext(config config.Config) (*appContext, error) {
+	appCtx := New(config, timoni.NewOutput(config))
+	if config.Rules != "" {
+		parametrizationRules, err := rules.Load(config.Rules)
+		if err != nil {
+			return nil, err
+		}
+		appCtx = appCtx.WithRules(parametrizationRules)
+	}
+	if config.Overlay != "" {
+		overlay, err := rules.LoadOverlay(config.Overlay)
+		if err != nil {
+			return nil, err
+		}
+		appCtx = appCtx.WithOverlay(overlay)
+	}
+	return appCtx.WithProcessors(
 		//configmap.New(),
 		//crd.New(),
 		//daemonset.New(),
 		deployment.New(),
+		rollout.New(),
+		replicaset.New(),
+		pod.New(),
+		istio.NewVirtualService(),
+		istio.NewDestinationRule(),
+		istio.NewPeerAuthentication(),
 		//statefulset.New(),
 		//storage.New(),
-		//service.New(),
-		//service.NewIngress(),
+		service.New(),
+		service.NewIngress(),
 		//rbac.ClusterRoleBinding(),
 		//rbac.Role(),
 		//rbac.RoleBinding(),
@@ -55,22 +143,7 @@ func Start(stdin io.Reader, config config.Config) error {
 		//job.NewCron(),
 		//job.NewJob(),
 		//poddisruptionbudget.New(),
-	).WithDefaultProcessor(processor.Default())
-	if len(config.Files) != 0 {
-		file.Walk(config.Files, config.FilesRecursively, func(filename string, fileReader io.Reader) {
-			objects := decoder.Decode(ctx.Done(), fileReader)
-			for obj := range objects {
-				appCtx.Add(obj, filename)
-			}
-		})
-	} else {
-		objects := decoder.Decode(ctx.Done(), stdin)
-		for obj := range objects {
-			appCtx.Add(obj, "")
-		}
-	}
-
-	return appCtx.CreateHelm(ctx.Done())
+	).WithDefaultProcessor(processor.Default()), nil
 }
 
 func setLogLevel(config config.Config) {
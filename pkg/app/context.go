@@ -1,9 +1,14 @@
 package app
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 	"github.com/syndicut/timonify/pkg/config"
 	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/rules"
 	"github.com/syndicut/timonify/pkg/timonify"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -17,14 +22,20 @@ type appContext struct {
 	appMeta          *metadata.Service
 	objects          []*unstructured.Unstructured
 	fileNames        []string
+	comments         [][]timonify.Comment
+	rules            []rules.Rule
+	overlay          *rules.Overlay
+	sensitivity      *timonify.Sensitivity
+	report           timonify.Report
 }
 
 // New returns context with config set.
 func New(config config.Config, output timonify.Output) *appContext {
 	return &appContext{
-		config:  config,
-		appMeta: metadata.New(config),
-		output:  output,
+		config:      config,
+		appMeta:     metadata.New(config),
+		output:      output,
+		sensitivity: timonify.NewSensitivity(config.Sensitive...),
 	}
 }
 
@@ -40,28 +51,123 @@ func (c *appContext) WithDefaultProcessor(processor timonify.Processor) *appCont
 	return c
 }
 
-// Add k8s object to app context.
-func (c *appContext) Add(obj *unstructured.Unstructured, filename string) {
+// WithRules  add parametrization rules to the context and returns it.
+func (c *appContext) WithRules(rules []rules.Rule) *appContext {
+	c.rules = rules
+	return c
+}
+
+// WithOverlay  add CUE overlay unified with processed objects to the context and returns it.
+func (c *appContext) WithOverlay(overlay *rules.Overlay) *appContext {
+	c.overlay = overlay
+	return c
+}
+
+// Add k8s object with comments of its manifest fields to app context.
+func (c *appContext) Add(obj *unstructured.Unstructured, filename string, comments []timonify.Comment) {
 	// we need to add all objects before start processing only to define app metadata.
 	c.appMeta.Load(obj)
 	c.objects = append(c.objects, obj)
 	c.fileNames = append(c.fileNames, filename)
+	c.comments = append(c.comments, comments)
 }
 
+// errStopped - processing is stopped, the module is not created.
+var errStopped = errors.New("stopped")
+
 // CreateHelm creates helm module from context k8s objects.
 func (c *appContext) CreateHelm(stop <-chan struct{}) error {
 	logrus.WithFields(logrus.Fields{
 		"ModuleName": c.appMeta.ModuleName(),
 		"Namespace":  c.appMeta.Namespace(),
 	}).Info("creating a module")
+	templates, filenames, err := c.createTemplates(stop)
+	if err != nil {
+		if errors.Is(err, errStopped) {
+			return nil
+		}
+		return err
+	}
+	return c.output.Create(c.config.ModuleDir, c.config.ModuleName, c.config.Crd, templates, filenames)
+}
+
+// CreateEnvironments creates helm module from k8s objects of environment contexts, contexts are in the order
+// of config environments.
+func CreateEnvironments(stop <-chan struct{}, config config.Config, contexts []*appContext) error {
+	logrus.WithFields(logrus.Fields{
+		"ModuleName":   config.ModuleName,
+		"Environments": len(contexts),
+	}).Info("creating a module")
+	// object names are trimmed by the common prefix of module objects, it must be the same in every environment
+	// for values of environments to match
+	for _, c := range contexts {
+		for _, other := range contexts {
+			if other == c {
+				continue
+			}
+			for _, obj := range other.objects {
+				c.appMeta.Load(obj)
+			}
+		}
+	}
+	envs := make([]timonify.Environment, 0, len(contexts))
+	for i, c := range contexts {
+		templates, filenames, err := c.createTemplates(stop)
+		if err != nil {
+			if errors.Is(err, errStopped) {
+				return nil
+			}
+			return fmt.Errorf("%w: environment %s", err, config.Environments[i].Name)
+		}
+		envs = append(envs, timonify.Environment{Name: config.Environments[i].Name, Templates: templates, Filenames: filenames})
+	}
+	return contexts[0].output.CreateEnvironments(config.ModuleDir, config.ModuleName, config.Crd, envs)
+}
+
+// createTemplates processes context k8s objects into templates and their file names.
+func (c *appContext) createTemplates(stop <-chan struct{}) ([]timonify.Template, []string, error) {
 	var templates []timonify.Template
 	var filenames []string
+	// values of all objects are merged to detect objects setting the same #config paths.
+	values := timonify.NewValues()
 	for i, obj := range c.objects {
+		// processors may modify the object, e.g. default one strips metadata.
+		kind, name := obj.GetKind(), obj.GetName()
+		original := obj.DeepCopy()
 		template, err := c.process(obj)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if template != nil {
+			if template, err = rules.Apply(c.rules, original, template); err != nil {
+				return nil, nil, err
+			}
+			if c.overlay != nil {
+				if template, err = rules.ApplyOverlay(c.overlay, original, template); err != nil {
+					return nil, nil, err
+				}
+			}
+			sensitive, err := template.Values().MarkSensitive(c.sensitivity, kind+"/"+name, kind == "Secret")
+			if err != nil {
+				return nil, nil, err
+			}
+			c.report.Sensitive = append(c.report.Sensitive, sensitive...)
+			if len(c.comments[i]) != 0 {
+				var source bytes.Buffer
+				if err = template.Write(&source); err != nil {
+					return nil, nil, err
+				}
+				if err = template.Values().AddComments(source.Bytes(), c.comments[i]); err != nil {
+					return nil, nil, err
+				}
+			}
+			if err = values.MergeFrom(kind+"/"+name, template.Values()); err != nil {
+				if errors.Is(err, timonify.ErrValueCollision) && c.config.ValuesNaming != config.ValuesNamingKind {
+					return nil, nil, fmt.Errorf("%w: use -values-naming=%s to prefix values with object kinds", err, config.ValuesNamingKind)
+				}
+				return nil, nil, err
+			}
+			c.appMeta.AddObjectType(kind, name, template.ObjectType())
 			templates = append(templates, template)
 			filename := template.Filename()
 			if c.fileNames[i] != "" {
@@ -71,11 +177,23 @@ func (c *appContext) CreateHelm(stop <-chan struct{}) error {
 		}
 		select {
 		case <-stop:
-			return nil
+			return nil, nil, errStopped
 		default:
 		}
 	}
-	return c.output.Create(c.config.ModuleDir, c.config.ModuleName, c.config.Crd, templates, filenames)
+	c.report.Shared = append(c.report.Shared, values.SharedValues()...)
+	if c.overlay != nil && len(templates) != 0 {
+		var err error
+		if templates[0], err = rules.ApplyOverlayConfig(c.overlay, templates[0]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return templates, filenames, nil
+}
+
+// Report returns conversion report of the created module.
+func (c *appContext) Report() *timonify.Report {
+	return &c.report
 }
 
 func (c *appContext) process(obj *unstructured.Unstructured) (timonify.Template, error) {
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/processor"
+	deploymentProcessor "github.com/syndicut/timonify/pkg/processor/deployment"
+	"github.com/syndicut/timonify/pkg/rules"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// recordingOutput - records created module templates.
+type recordingOutput struct {
+	templates []timonify.Template
+	envs      []timonify.Environment
+}
+
+func (o *recordingOutput) Create(_, _ string, _ bool, templates []timonify.Template, _ []string) error {
+	o.templates = templates
+	return nil
+}
+
+func (o *recordingOutput) CreateEnvironments(_, _ string, _ bool, envs []timonify.Environment) error {
+	o.templates, o.envs = envs[0].Templates, envs
+	return nil
+}
+
+func Test_appContext_overlayConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "overlay.cue")
+	assert.NoError(t, os.WriteFile(file, []byte(`#Config: priority: *"standard" | string`), 0600))
+	overlay, err := rules.LoadOverlay(file)
+	assert.NoError(t, err)
+
+	out := &recordingOutput{}
+	appCtx := New(config.Config{ModuleName: "module-name"}, out).
+		WithDefaultProcessor(processor.Default()).
+		WithOverlay(overlay)
+	appCtx.Add(internal.GenerateObj(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  key: value`), "", nil)
+	appCtx.Add(internal.GenerateObj(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: worker`), "", nil)
+	assert.NoError(t, appCtx.CreateHelm(make(chan struct{})))
+
+	// #Config-only overlay selects no objects, its fields are added to module values once
+	values := timonify.NewValues()
+	for _, tmpl := range out.templates {
+		assert.NoError(t, values.Merge(tmpl.Values()))
+	}
+	cfg, err := format.Node(values.Config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(cfg), "priority"))
+}
+
+func TestCreateEnvironments_names(t *testing.T) {
+	deployment := func(name string) *unstructured.Unstructured {
+		return internal.GenerateObj(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ` + name + `
+spec:
+  selector:
+    matchLabels:
+      app: ` + name + `
+  template:
+    metadata:
+      labels:
+        app: ` + name + `
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.14.2`)
+	}
+	conf := config.Config{ModuleName: "module-name", Environments: []config.Environment{{Name: "dev"}, {Name: "prod"}}}
+	out := &recordingOutput{}
+	dev := New(conf, out).WithProcessors(deploymentProcessor.New()).WithDefaultProcessor(processor.Default())
+	dev.Add(deployment("shop-web"), "", nil)
+	prod := New(conf, out).WithProcessors(deploymentProcessor.New()).WithDefaultProcessor(processor.Default())
+	prod.Add(deployment("shop-web"), "", nil)
+	prod.Add(internal.GenerateObj(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: shop-config`), "", nil)
+	assert.NoError(t, CreateEnvironments(make(chan struct{}), conf, []*appContext{dev, prod}))
+
+	// names are trimmed by the same prefix in every environment, so values of environments match
+	assert.Len(t, out.envs, 2)
+	for _, env := range out.envs {
+		assert.Contains(t, env.Templates[0].Values().Values, "web", env.Name)
+	}
+}
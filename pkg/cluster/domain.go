@@ -1,7 +1,73 @@
 package cluster
 
+import (
+	"strconv"
+	"strings"
+)
+
 const (
 	DefaultDomain = "cluster.local"
 	DomainKey     = "kubernetesClusterDomain"
 	DomainEnv     = "KUBERNETES_CLUSTER_DOMAIN"
+
+	// domainPlaceholder - replaces cluster domain in plain strings which are rendered as CUE literals later,
+	// see ResolvePlaceholders.
+	domainPlaceholder = "__TIMONIFY_CLUSTER_DOMAIN__"
 )
+
+// domainInterpolation - CUE interpolation of the module cluster domain config value.
+var domainInterpolation = `\(#config.` + DomainKey + `)`
+
+// References returns true if s contains the default cluster domain as a whole domain name suffix,
+// e.g. "svc.cluster.local" but not "mycluster.local".
+func References(s string) bool {
+	_, ok := WithPlaceholders(s)
+	return ok
+}
+
+// WithPlaceholders replaces every occurrence of the default cluster domain in s with a placeholder.
+func WithPlaceholders(s string) (string, bool) {
+	var (
+		res   strings.Builder
+		found bool
+	)
+	for {
+		i := strings.Index(s, DefaultDomain)
+		if i < 0 {
+			res.WriteString(s)
+			return res.String(), found
+		}
+		end := i + len(DefaultDomain)
+		if (i == 0 || !isHostnameChar(s[i-1])) && (end == len(s) || !isHostnameChar(s[end])) {
+			res.WriteString(s[:i])
+			res.WriteString(domainPlaceholder)
+			found = true
+		} else {
+			res.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+// ResolvePlaceholders replaces placeholders in CUE string literals of src with the cluster domain interpolation.
+func ResolvePlaceholders(src string) string {
+	return strings.ReplaceAll(src, domainPlaceholder, domainInterpolation)
+}
+
+// Templated rewrites quoted string to CUE string interpolating #config.kubernetesClusterDomain instead of the
+// default cluster domain. It returns false if the string does not reference the cluster domain.
+func Templated(quoted string) (string, bool) {
+	s, err := strconv.Unquote(quoted)
+	if err != nil {
+		return quoted, false
+	}
+	s, ok := WithPlaceholders(s)
+	if !ok {
+		return quoted, false
+	}
+	return ResolvePlaceholders(strconv.Quote(s)), true
+}
+
+func isHostnameChar(c byte) bool {
+	return c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
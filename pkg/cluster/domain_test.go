@@ -0,0 +1,29 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplated(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{in: `"cluster.local"`, want: `"\(#config.kubernetesClusterDomain)"`, ok: true},
+		{in: `"http://api.ns.svc.cluster.local:8080/"`, want: `"http://api.ns.svc.\(#config.kubernetesClusterDomain):8080/"`, ok: true},
+		{in: `"a.cluster.local,b.cluster.local"`, want: `"a.\(#config.kubernetesClusterDomain),b.\(#config.kubernetesClusterDomain)"`, ok: true},
+		{in: `"mycluster.local"`, want: `"mycluster.local"`},
+		{in: `"cluster.localhost"`, want: `"cluster.localhost"`},
+		{in: `#config.app.env`, want: `#config.app.env`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := Templated(tt.in)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
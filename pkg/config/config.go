@@ -9,6 +9,27 @@ import (
 // defaultModuleName - default name for a helm module directory.
 const defaultModuleName = "timoni"
 
+// Pod Security Standard profiles supported by PodSecurity.
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+// Naming strategies of object values in #config supported by ValuesNaming.
+const (
+	ValuesNamingName = "name"
+	ValuesNamingKind = "kind"
+)
+
+// Environment - labeled input of a module environment, e.g. dev=overlays/dev.
+type Environment struct {
+	// Name - environment name, values of the environment are written to values-<name>.cue.
+	Name string
+	// Path - file or directory with k8s manifests of the environment.
+	Path string
+}
+
 // Config for Helmify application.
 type Config struct {
 	// ModuleName name of the Timoni module and its base directory where timoni.cue is located.
@@ -23,9 +44,29 @@ type Config struct {
 	Crd bool
 	// ImagePullSecrets flag
 	ImagePullSecrets bool
-	// GenerateDefaults enables the generation of empty values placeholders for common customization options of helm module
-	// current generated values: tolerances, node selectors, topology constraints
+	// GenerateDefaults enables the generation of optional #Config fields for common customization options of timoni module
+	// current generated fields: tolerations, affinity, topology spread constraints, node selectors, priority class name
 	GenerateDefaults bool
+	// DefaultsInConfig puts values of the original manifests into #Config as defaults instead of values.cue,
+	// values.cue is left for user overrides only.
+	DefaultsInConfig bool
+	// HoistSharedValues moves container values equal across workloads, e.g. image repositories, env vars and
+	// resources, to module-wide #config fields, container fields default to them.
+	HoistSharedValues bool
+	// Rules is a path to the parametrization rules file lifting arbitrary object fields into #Config.
+	Rules string
+	// Overlay is a path to the CUE file unified with processed objects, its structs select objects by kind and name
+	// and may reference #config.
+	Overlay string
+	// Sensitive lists value names or dot separated #config paths which are sensitive in addition to Secret values
+	// and names like dbPassword, API_TOKEN or apiKey. Sensitive values are kept out of values.cue.
+	Sensitive []string
+	// ValuesNaming selects how object values are named in #config: name (default) uses the object name,
+	// kind prefixes it with the object kind to disambiguate objects of different kinds with the same name.
+	ValuesNaming string
+	// PodSecurity selects the Pod Security Standard profile enforced by security context schemas in #Config:
+	// privileged (default), baseline or restricted.
+	PodSecurity string
 	// CertManagerAsSubmodule enables the generation of a submodule for cert-manager
 	CertManagerAsSubmodule bool
 	// CertManagerVersion sets cert-manager version in dependency
@@ -36,6 +77,9 @@ type Config struct {
 	FilesRecursively bool
 	// OriginalName retains Kubernetes resource's original name
 	OriginalName bool
+	// Environments - inputs of module environments, e.g. dev and prod overlays of the same app. The module is built
+	// from the first environment, values differing between environments go to values-<name>.cue files.
+	Environments []Environment
 }
 
 func (c *Config) Validate() error {
@@ -50,5 +94,36 @@ func (c *Config) Validate() error {
 		}
 		return fmt.Errorf("invalid module name %s", c.ModuleName)
 	}
+	switch c.ValuesNaming {
+	case "":
+		c.ValuesNaming = ValuesNamingName
+	case ValuesNamingName, ValuesNamingKind:
+	default:
+		return fmt.Errorf("invalid values naming %s: must be one of %s, %s", c.ValuesNaming, ValuesNamingName, ValuesNamingKind)
+	}
+	switch c.PodSecurity {
+	case "":
+		c.PodSecurity = PodSecurityPrivileged
+	case PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted:
+	default:
+		return fmt.Errorf("invalid pod security profile %s: must be one of %s, %s, %s",
+			c.PodSecurity, PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted)
+	}
+	names := map[string]bool{}
+	for _, env := range c.Environments {
+		if env.Path == "" {
+			return fmt.Errorf("invalid environment %s: expected name=path", env.Name)
+		}
+		if errs := validation.IsDNS1123Label(env.Name); len(errs) != 0 {
+			return fmt.Errorf("invalid environment name %q: %s", env.Name, errs[0])
+		}
+		if names[env.Name] {
+			return fmt.Errorf("environment %s is set more than once", env.Name)
+		}
+		names[env.Name] = true
+	}
+	if len(c.Environments) != 0 && len(c.Files) != 0 {
+		return fmt.Errorf("environments and files can not be used together, set files of every environment instead")
+	}
 	return nil
 }
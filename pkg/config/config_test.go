@@ -8,9 +8,11 @@ import (
 
 func TestConfig_Validate(t *testing.T) {
 	type fields struct {
-		ModuleName  string
-		Verbose     bool
-		VeryVerbose bool
+		ModuleName   string
+		Verbose      bool
+		VeryVerbose  bool
+		PodSecurity  string
+		ValuesNaming string
 	}
 	tests := []struct {
 		name    string
@@ -22,19 +24,42 @@ func TestConfig_Validate(t *testing.T) {
 		{name: "valid", fields: fields{ModuleName: "my-module123"}, wantErr: false},
 		{name: "invalid", fields: fields{ModuleName: "my_module123"}, wantErr: true},
 		{name: "invalid", fields: fields{ModuleName: "my char123t"}, wantErr: true},
+		{name: "valid", fields: fields{ModuleName: "my-module", PodSecurity: "restricted"}, wantErr: false},
+		{name: "invalid", fields: fields{ModuleName: "my-module", PodSecurity: "strict"}, wantErr: true},
+		{name: "valid", fields: fields{ModuleName: "my-module", ValuesNaming: "kind"}, wantErr: false},
+		{name: "invalid", fields: fields{ModuleName: "my-module", ValuesNaming: "type"}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Config{
-				ModuleName:  tt.fields.ModuleName,
-				Verbose:     tt.fields.Verbose,
-				VeryVerbose: tt.fields.VeryVerbose,
+				ModuleName:   tt.fields.ModuleName,
+				Verbose:      tt.fields.Verbose,
+				VeryVerbose:  tt.fields.VeryVerbose,
+				PodSecurity:  tt.fields.PodSecurity,
+				ValuesNaming: tt.fields.ValuesNaming,
 			}
 			if err := c.Validate(); (err != nil) != tt.wantErr {
 				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
+	t.Run("environments", func(t *testing.T) {
+		valid := []Environment{{Name: "dev", Path: "overlays/dev"}, {Name: "prod", Path: "overlays/prod"}}
+		for name, c := range map[string]Config{
+			"valid":          {Environments: valid},
+			"no path":        {Environments: []Environment{{Name: "dev"}}},
+			"invalid name":   {Environments: []Environment{{Name: "Dev_1", Path: "dev"}}},
+			"duplicate name": {Environments: []Environment{{Name: "dev", Path: "dev"}, {Name: "dev", Path: "prod"}}},
+			"with files":     {Environments: valid, Files: []string{"app.yaml"}},
+		} {
+			err := c.Validate()
+			if name == "valid" {
+				assert.NoError(t, err, name)
+			} else {
+				assert.Error(t, err, name)
+			}
+		}
+	})
 	t.Run("module name not set", func(t *testing.T) {
 		c := &Config{}
 		err := c.Validate()
@@ -47,4 +72,10 @@ func TestConfig_Validate(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "test", c.ModuleName)
 	})
+	t.Run("pod security not set", func(t *testing.T) {
+		c := &Config{}
+		err := c.Validate()
+		assert.NoError(t, err)
+		assert.Equal(t, PodSecurityPrivileged, c.PodSecurity)
+	})
 }
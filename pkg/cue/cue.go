@@ -5,10 +5,11 @@ import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"fmt"
-	"strconv"
+	"strings"
 )
 
 // Indent - adds indentation to given content.
@@ -21,6 +22,16 @@ func Indent(content []byte, n int) []byte {
 	return bytes.ReplaceAll(content, []byte("\n"), prefix)
 }
 
+// conditionPrefix marks map keys which are rendered as CUE if comprehensions.
+const conditionPrefix = "if "
+
+// If - returns map key for fields which are rendered only if given CUE condition is true.
+// Value of the key must be a map of conditional fields.
+// Example: {If("#config.x.enabled"): {"field": "#config.x.value"}} -> if #config.x.enabled {field: #config.x.value}
+func If(condition string) string {
+	return conditionPrefix + condition
+}
+
 // Marshal object to cue string with indentation.
 func Marshal(object interface{}, indent int, parse bool) (string, error) {
 	ctx := cuecontext.New()
@@ -48,7 +59,17 @@ func Marshal(object interface{}, indent int, parse bool) (string, error) {
 func parseStringLits(node ast.Node) (ast.Node, error) {
 	switch node := node.(type) {
 	case *ast.StructLit:
-		for _, decl := range node.Elts {
+		for i, decl := range node.Elts {
+			if field, ok := decl.(*ast.Field); ok {
+				comprehension, err := parseCondition(field)
+				if err != nil {
+					return nil, err
+				}
+				if comprehension != nil {
+					node.Elts[i] = comprehension
+					continue
+				}
+			}
 			_, err := parseStringLits(decl)
 			if err != nil {
 				return nil, err
@@ -88,6 +109,29 @@ func parseStringLits(node ast.Node) (ast.Node, error) {
 	return node, nil
 }
 
+// parseCondition converts field labeled with If into if comprehension. Returns nil for regular fields.
+func parseCondition(field *ast.Field) (*ast.Comprehension, error) {
+	label, _, err := ast.LabelName(field.Label)
+	if err != nil || !strings.HasPrefix(label, conditionPrefix) {
+		return nil, nil
+	}
+	condition, err := parser.ParseExpr("", strings.TrimPrefix(label, conditionPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse condition %q", err, label)
+	}
+	value, ok := field.Value.(*ast.StructLit)
+	if !ok {
+		return nil, fmt.Errorf("conditional field %q must be a struct", label)
+	}
+	if _, err = parseStringLits(value); err != nil {
+		return nil, err
+	}
+	return &ast.Comprehension{
+		Clauses: []ast.Clause{&ast.IfClause{Condition: condition}},
+		Value:   value,
+	}, nil
+}
+
 // isStringLit checks if the value is a BasicLit with kind token.STRING
 func isStringLit(v ast.Expr) bool {
 	if v, ok := v.(*ast.BasicLit); ok && v.Kind == token.STRING {
@@ -97,7 +141,8 @@ func isStringLit(v ast.Expr) bool {
 }
 
 func parseStringLit(v *ast.BasicLit) (ast.Expr, error) {
-	value, err := strconv.Unquote(v.Value)
+	// multiline strings are encoded as CUE multiline literals which are not valid Go strings
+	value, err := literal.Unquote(v.Value)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to unquote string", err)
 	}
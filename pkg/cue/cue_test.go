@@ -162,3 +162,33 @@ func Test_parseStringLits(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalIf(t *testing.T) {
+	obj := map[string]interface{}{
+		"name": `"app"`,
+		If("#config.app.probes.liveness.enabled"): map[string]interface{}{
+			"livenessProbe": "#config.app.probes.liveness",
+		},
+	}
+	got, err := Marshal(obj, 0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+	name: "app"
+	if #config.app.probes.liveness.enabled {
+		livenessProbe: #config.app.probes.liveness
+	}
+}`, got)
+}
+
+func TestMarshalMultilineExpression(t *testing.T) {
+	obj := map[string]interface{}{
+		"list": "[{\n\tname: \"a\"\n}, for v in #config.extra {v}]",
+	}
+	got, err := Marshal(obj, 0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+	list: [{
+		name: "a"
+	}, for v in #config.extra {v}]
+}`, got)
+}
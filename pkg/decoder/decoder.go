@@ -1,10 +1,16 @@
 package decoder
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/syndicut/timonify/pkg/timonify"
+	yamlv3 "gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
@@ -16,11 +22,30 @@ const (
 	decoderResultChannelBufferSize = 1
 )
 
+// Object - decoded k8s object with comments of its fields.
+type Object struct {
+	*unstructured.Unstructured
+	// Comments - comments of the object manifest fields.
+	Comments []timonify.Comment
+}
+
 // Decode - reads bytes stream of k8s yaml manifests and decodes it to k8s unstructured objects.
 // Non-blocking function. Sends results into buffered channel. Closes channel on io.EOF.
 func Decode(stop <-chan struct{}, reader io.Reader) <-chan *unstructured.Unstructured {
-	decoder := yamlutil.NewYAMLOrJSONDecoder(reader, yamlDecoderBufferSize)
 	res := make(chan *unstructured.Unstructured, decoderResultChannelBufferSize)
+	go func() {
+		defer close(res)
+		for obj := range DecodeWithComments(stop, reader) {
+			res <- obj.Unstructured
+		}
+	}()
+	return res
+}
+
+// DecodeWithComments - same as Decode, but keeps YAML comments of the decoded objects fields.
+func DecodeWithComments(stop <-chan struct{}, reader io.Reader) <-chan Object {
+	documents := yamlutil.NewYAMLReader(bufio.NewReader(reader))
+	res := make(chan Object, decoderResultChannelBufferSize)
 	go func() {
 		defer close(res)
 		logrus.Debug("Start processing...")
@@ -31,34 +56,116 @@ func Decode(stop <-chan struct{}, reader io.Reader) <-chan *unstructured.Unstruc
 				return
 			default:
 			}
-			var rawObj runtime.RawExtension
-			err := decoder.Decode(&rawObj)
+			document, err := documents.Read()
 			if errors.Is(err, io.EOF) {
 				logrus.Debug("EOF received. Finishing input objects decoding.")
 				return
 			}
 			if err != nil {
-				logrus.WithError(err).Error("unable to decode yaml from input")
+				logrus.WithError(err).Error("unable to read yaml from input")
 				continue
 			}
-			obj, _, err := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).Decode(rawObj.Raw, nil, nil)
-			if err != nil {
-				logrus.WithError(err).Error("unable to decode yaml")
-				continue
+			objects := decodeDocument(document)
+			if len(objects) == 1 {
+				objects[0].Comments = comments(document)
 			}
-			unstructuredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
-			if err != nil {
-				logrus.WithError(err).Error("unable to map yaml to k8s unstructured")
-				continue
+			for _, object := range objects {
+				res <- object
 			}
-			object := &unstructured.Unstructured{Object: unstructuredMap}
-			logrus.WithFields(logrus.Fields{
-				"ApiVersion": object.GetAPIVersion(),
-				"Kind":       object.GetKind(),
-				"Name":       object.GetName(),
-			}).Debug("decoded")
-			res <- object
 		}
 	}()
 	return res
 }
+
+// decodeDocument decodes objects of the yaml document, JSON documents may contain several objects.
+func decodeDocument(document []byte) []Object {
+	var res []Object
+	decoder := yamlutil.NewYAMLOrJSONDecoder(bytes.NewReader(document), yamlDecoderBufferSize)
+	for {
+		var rawObj runtime.RawExtension
+		err := decoder.Decode(&rawObj)
+		if errors.Is(err, io.EOF) {
+			return res
+		}
+		if err != nil {
+			logrus.WithError(err).Error("unable to decode yaml from input")
+			return res
+		}
+		if len(rawObj.Raw) == 0 {
+			continue
+		}
+		obj, _, err := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).Decode(rawObj.Raw, nil, nil)
+		if err != nil {
+			logrus.WithError(err).Error("unable to decode yaml")
+			continue
+		}
+		unstructuredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			logrus.WithError(err).Error("unable to map yaml to k8s unstructured")
+			continue
+		}
+		object := &unstructured.Unstructured{Object: unstructuredMap}
+		logrus.WithFields(logrus.Fields{
+			"ApiVersion": object.GetAPIVersion(),
+			"Kind":       object.GetKind(),
+			"Name":       object.GetName(),
+		}).Debug("decoded")
+		res = append(res, Object{Unstructured: object})
+	}
+}
+
+// comments returns comments of the yaml document fields, documents which can not be parsed have no comments.
+func comments(document []byte) []timonify.Comment {
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(document, &node); err != nil || len(node.Content) == 0 {
+		return nil
+	}
+	var res []timonify.Comment
+	collectComments(node.Content[0], nil, &res)
+	return res
+}
+
+func collectComments(node *yamlv3.Node, path []string, res *[]timonify.Comment) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := append(append([]string{}, path...), key.Value)
+			addComment(fieldPath, res, key.HeadComment, key.LineComment, value.LineComment)
+			collectComments(value, fieldPath, res)
+		}
+	case yamlv3.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := append(append([]string{}, path...), itemKey(item, i))
+			addComment(itemPath, res, item.HeadComment, item.LineComment)
+			collectComments(item, itemPath, res)
+		}
+	}
+}
+
+// itemKey returns name of the list item, e.g. container name, or its index.
+func itemKey(item *yamlv3.Node, index int) string {
+	if item.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yamlv3.ScalarNode {
+				return item.Content[i+1].Value
+			}
+		}
+	}
+	return strconv.Itoa(index)
+}
+
+func addComment(path []string, res *[]timonify.Comment, comments ...string) {
+	var lines []string
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	if len(lines) != 0 {
+		*res = append(*res, timonify.Comment{Path: path, Text: strings.Join(lines, "\n")})
+	}
+}
@@ -1,10 +1,13 @@
 package decoder
 
 import (
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/pkg/timonify"
 )
 
 const (
@@ -97,3 +100,62 @@ func TestDecodeInvalidObj(t *testing.T) {
 	}
 	assert.Equal(t, 2, i, "decoded 2 valid objects")
 }
+
+func TestDecodeWithComments(t *testing.T) {
+	reader := strings.NewReader(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  # bump this when the DB schema changes
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: app # main container
+        env:
+        - name: LOG_LEVEL
+          value: info # debug in staging
+---
+{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "web"}}
+`)
+	stop := make(chan struct{})
+	var objects []Object
+	for obj := range DecodeWithComments(stop, reader) {
+		objects = append(objects, obj)
+	}
+	assert.Len(t, objects, 2)
+	assert.Equal(t, "web", objects[0].GetName())
+	assert.Equal(t, []timonify.Comment{
+		{Path: []string{"spec", "replicas"}, Text: "bump this when the DB schema changes"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "name"}, Text: "main container"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "env", "LOG_LEVEL", "value"}, Text: "debug in staging"},
+	}, objects[0].Comments)
+	assert.Equal(t, "Namespace", objects[1].GetKind())
+	assert.Empty(t, objects[1].Comments)
+}
+
+// failingReader - fails the first read, then reads the underlying reader.
+type failingReader struct {
+	io.Reader
+	failed bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if !r.failed {
+		r.failed = true
+		return 0, errors.New("read failed")
+	}
+	return r.Reader.Read(p)
+}
+
+func TestDecodeReadError(t *testing.T) {
+	reader := &failingReader{Reader: strings.NewReader(validObjects2)}
+	stop := make(chan struct{})
+	objects := Decode(stop, reader)
+	i := 0
+	for range objects {
+		i++
+	}
+	assert.Equal(t, 2, i, "input is read further on errors")
+}
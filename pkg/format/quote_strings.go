@@ -38,6 +38,16 @@ func QuoteStringsInStruct(s interface{}) {
 				QuoteStringsInStruct(elem.Addr().Interface())
 			}
 		}
+	case reflect.Interface:
+		// unstructured objects keep their values behind interface{}, so quote a copy of the dynamic value
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		elem := v.Elem()
+		newValue := reflect.New(elem.Type()).Elem()
+		newValue.Set(elem)
+		QuoteStringsInStruct(newValue.Addr().Interface())
+		v.Set(newValue)
 	case reflect.Map:
 		for _, key := range v.MapKeys() {
 			value := v.MapIndex(key)
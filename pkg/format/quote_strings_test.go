@@ -204,6 +204,21 @@ func TestQuoteStringsInStruct(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Test with unstructured map",
+			args: args{
+				s: &map[string]interface{}{
+					"a": "Hello",
+					"b": int64(1),
+					"c": []interface{}{"World", map[string]interface{}{"d": "Foo", "e": true}},
+				},
+			},
+			want: &map[string]interface{}{
+				"a": "\"Hello\"",
+				"b": int64(1),
+				"c": []interface{}{"\"World\"", map[string]interface{}{"d": "\"Foo\"", "e": true}},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"cuelang.org/go/cue/ast"
+	"github.com/iancoleman/strcase"
 	"github.com/sirupsen/logrus"
 	"github.com/syndicut/timonify/pkg/config"
 	"github.com/syndicut/timonify/pkg/timonify"
@@ -34,6 +36,8 @@ type Service struct {
 	namespace    string
 	names        map[string]struct{}
 	conf         config.Config
+	podPorts     []podPorts
+	objectTypes  map[objectKey]ast.Expr
 }
 
 func (a *Service) Config() config.Config {
@@ -52,6 +56,16 @@ func (a *Service) TrimName(objName string) string {
 	return trimmed
 }
 
+// ValuesName - returns lower camel case trimmed object name, prefixed with the object kind if kind values naming
+// is configured.
+func (a *Service) ValuesName(obj *unstructured.Unstructured) string {
+	name := strcase.ToLowerCamel(a.TrimName(obj.GetName()))
+	if a.conf.ValuesNaming == config.ValuesNamingKind {
+		return strcase.ToLowerCamel(obj.GetKind()) + strcase.ToCamel(name)
+	}
+	return name
+}
+
 var _ timonify.AppMetadata = &Service{}
 
 // Load processed objects one-by-one before actual processing to define app namespace, name common prefix and
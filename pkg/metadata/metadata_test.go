@@ -115,6 +115,18 @@ func Test_Service(t *testing.T) {
 		assert.Equal(t, "qwe", testSvc.TemplatedName("qwe"))
 		assert.NotEqual(t, "abc", testSvc.TemplatedName("abc"))
 	})
+	t.Run("values name", func(t *testing.T) {
+		obj := createRes("abc-my-worker", "ns")
+		testSvc := New(config.Config{})
+		testSvc.Load(obj)
+		testSvc.Load(createRes("abc-other", "ns"))
+		assert.Equal(t, "myWorker", testSvc.ValuesName(obj))
+
+		testSvc = New(config.Config{ValuesNaming: config.ValuesNamingKind})
+		testSvc.Load(obj)
+		testSvc.Load(createRes("abc-other", "ns"))
+		assert.Equal(t, "secretMyWorker", testSvc.ValuesName(obj))
+	})
 }
 
 func createRes(name, ns string) *unstructured.Unstructured {
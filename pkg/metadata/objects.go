@@ -0,0 +1,24 @@
+package metadata
+
+import (
+	"cuelang.org/go/cue/ast"
+)
+
+type objectKey struct {
+	kind string
+	name string
+}
+
+// AddObjectType registers CUE type of processed module object with given kind and original name.
+func (a *Service) AddObjectType(kind, name string, objType ast.Expr) {
+	if a.objectTypes == nil {
+		a.objectTypes = make(map[objectKey]ast.Expr)
+	}
+	a.objectTypes[objectKey{kind: kind, name: name}] = objType
+}
+
+// ObjectType returns CUE type of module object with given kind and original name.
+func (a *Service) ObjectType(kind, name string) (ast.Expr, bool) {
+	objType, ok := a.objectTypes[objectKey{kind: kind, name: name}]
+	return objType, ok
+}
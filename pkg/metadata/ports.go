@@ -0,0 +1,58 @@
+package metadata
+
+import (
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+type podPorts struct {
+	labels map[string]string
+	ports  []timonify.ContainerPort
+}
+
+// AddContainerPorts registers parametrized container ports of module pods with given labels.
+// Pods without ports are registered too to be matched by SelectsModulePods.
+func (a *Service) AddContainerPorts(podLabels map[string]string, ports []timonify.ContainerPort) {
+	a.podPorts = append(a.podPorts, podPorts{labels: podLabels, ports: ports})
+}
+
+// ContainerPort returns parametrized container port of the first module pod matching given selector.
+func (a *Service) ContainerPort(selector map[string]string, port intstr.IntOrString) (timonify.ContainerPort, bool) {
+	if len(selector) == 0 {
+		return timonify.ContainerPort{}, false
+	}
+	for _, pp := range a.podPorts {
+		if !matches(selector, pp.labels) {
+			continue
+		}
+		for _, p := range pp.ports {
+			if port.Type == intstr.String && p.Name != "" && p.Name == port.StrVal ||
+				port.Type == intstr.Int && p.Port == port.IntVal {
+				return p, true
+			}
+		}
+	}
+	return timonify.ContainerPort{}, false
+}
+
+// SelectsModulePods returns true if given selector matches labels of any module pod.
+func (a *Service) SelectsModulePods(selector map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for _, pp := range a.podPorts {
+		if matches(selector, pp.labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if l, ok := labels[k]; !ok || l != v {
+			return false
+		}
+	}
+	return true
+}
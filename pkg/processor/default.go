@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/syndicut/timonify/pkg/cluster"
 	"github.com/syndicut/timonify/pkg/cue"
 	"github.com/syndicut/timonify/pkg/timonify"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,6 +30,18 @@ var defaultTmpl = `package templates
 }
 `
 
+var cmGVK = schema.GroupVersionKind{
+	Group:   "",
+	Version: "v1",
+	Kind:    "ConfigMap",
+}
+
+var secretGVK = schema.GroupVersionKind{
+	Group:   "",
+	Version: "v1",
+	Kind:    "Secret",
+}
+
 // Default default processor for unknown resources.
 func Default() timonify.Processor {
 	return &dft{}
@@ -53,6 +66,12 @@ func (d dft) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructure
 	if err != nil {
 		return true, nil, err
 	}
+	switch obj.GroupVersionKind() {
+	case cmGVK:
+		templateClusterDomain(obj.Object, "data")
+	case secretGVK:
+		templateClusterDomain(obj.Object, "stringData")
+	}
 	delete(obj.Object, "apiVersion")
 	delete(obj.Object, "kind")
 	delete(obj.Object, "metadata")
@@ -61,13 +80,28 @@ func (d dft) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructure
 	if err != nil {
 		return true, nil, err
 	}
-	body = strings.Trim(body, "{}")
+	body = strings.Trim(cluster.ResolvePlaceholders(body), "{}")
 	return true, &defaultResult{
 		data: []byte(meta + "\n" + body),
 		name: name,
 	}, nil
 }
 
+// templateClusterDomain replaces the default cluster domain in plain text data field, ConfigMap data or Secret
+// stringData, with placeholders, which are resolved to #config.kubernetesClusterDomain interpolation in the rendered
+// template. Base64 encoded ConfigMap binaryData and Secret data are kept as is.
+func templateClusterDomain(obj map[string]interface{}, field string) {
+	data, ok := obj[field].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for k, v := range data {
+		if str, ok := v.(string); ok {
+			data[k], _ = cluster.WithPlaceholders(str)
+		}
+	}
+}
+
 type defaultResult struct {
 	data []byte
 	name string
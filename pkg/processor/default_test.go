@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"bytes"
 	"github.com/syndicut/timonify/pkg/config"
 	"testing"
 
@@ -21,6 +22,25 @@ spec:
       storage: 2Gi
   storageClassName: cust1-mypool-lim`
 
+const cmYaml = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-operator-config
+data:
+  upstream: api.default.svc.cluster.local:443
+  other: mycluster.local
+binaryData:
+  upstream: YXBpLmRlZmF1bHQuc3ZjLmNsdXN0ZXIubG9jYWw6NDQz`
+
+const secretYaml = `apiVersion: v1
+kind: Secret
+metadata:
+  name: my-operator-secret
+stringData:
+  url: postgres://db.default.svc.cluster.local:5432
+data:
+  host: ZGIuZGVmYXVsdC5zdmMuY2x1c3Rlci5sb2NhbA==`
+
 func Test_dft_Process(t *testing.T) {
 
 	t.Run("skip namespace", func(t *testing.T) {
@@ -42,4 +62,29 @@ func Test_dft_Process(t *testing.T) {
 		assert.True(t, processed)
 		assert.NotNil(t, templ)
 	})
+	t.Run("cluster domain in config map", func(t *testing.T) {
+		obj := internal.GenerateObj(cmYaml)
+		testMeta := metadata.New(config.Config{ModuleName: "module-name"})
+		testMeta.Load(obj)
+		_, templ, err := Default().Process(testMeta, obj)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, templ.Write(&out))
+		assert.Contains(t, out.String(), `upstream: "api.default.svc.\(#config.kubernetesClusterDomain):443"`)
+		assert.Contains(t, out.String(), `other:    "mycluster.local"`)
+		// base64 encoded binary data is kept as is
+		assert.Contains(t, out.String(), `upstream: "YXBpLmRlZmF1bHQuc3ZjLmNsdXN0ZXIubG9jYWw6NDQz"`)
+	})
+	t.Run("cluster domain in secret", func(t *testing.T) {
+		obj := internal.GenerateObj(secretYaml)
+		testMeta := metadata.New(config.Config{ModuleName: "module-name"})
+		testMeta.Load(obj)
+		_, templ, err := Default().Process(testMeta, obj)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, templ.Write(&out))
+		assert.Contains(t, out.String(), `url: "postgres://db.default.svc.\(#config.kubernetesClusterDomain):5432"`)
+		// base64 encoded data is kept as is
+		assert.Contains(t, out.String(), `host: "ZGIuZGVmYXVsdC5zdmMuY2x1c3Rlci5sb2NhbA=="`)
+	})
 }
@@ -60,6 +60,9 @@ var deploymentTempl, _ = template.New("deployment").Parse(
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+{{- if .Checksums }}
+{{ .ChecksumImports }}
+{{- end }}
 )
 
 #Deployment: appsv1.#Deployment & {
@@ -72,11 +75,13 @@ import (
 {{- if .RevisionHistoryLimit }}
 {{ .RevisionHistoryLimit }}
 {{- end }}
+{{ .Strategy }}
 {{ .Selector }}
 		template: {
 			metadata: {
 				labels: {{ .PodLabels }}
 {{- .PodAnnotations }}
+{{- .Checksums }}
 			}
 			spec: corev1.#PodSpec & {{ .Spec }}
 		}
@@ -86,6 +91,30 @@ import (
 const selectorTempl = `selector: matchLabels: %[1]s
 %[2]s`
 
+// strategyTempl - optional rollout fields are set only if present in #config.
+const strategyTempl = `if #config.%[1]s.strategy != _|_ {strategy: #config.%[1]s.strategy}
+if #config.%[1]s.minReadySeconds != _|_ {minReadySeconds: #config.%[1]s.minReadySeconds}
+if #config.%[1]s.progressDeadlineSeconds != _|_ {progressDeadlineSeconds: #config.%[1]s.progressDeadlineSeconds}`
+
+const (
+	// strategySchema - rollingUpdate parameters are allowed only with RollingUpdate strategy.
+	strategySchema = `{
+	type: *"RollingUpdate" | "Recreate"
+	if type == "RollingUpdate" {
+		rollingUpdate?: {
+			maxSurge?:       int & >=0 | =~"^[0-9]+%$"
+			maxUnavailable?: int & >=0 | =~"^[0-9]+%$"
+		}
+	}
+	if type == "Recreate" {
+		rollingUpdate?: _|_
+	}
+}`
+	minReadySecondsSchema = "int & >=0"
+	// progressDeadlineSecondsSchema - progress deadline must be greater than minReadySeconds, if it is set.
+	progressDeadlineSecondsSchema = "int & >(*minReadySeconds | 0)"
+)
+
 // New creates processor for k8s Deployment resource.
 func New() timonify.Processor {
 	return &deployment{}
@@ -112,7 +141,7 @@ func (d deployment) Process(appMeta timonify.AppMetadata, obj *unstructured.Unst
 
 	values := timonify.NewValues()
 
-	name := appMeta.TrimName(obj.GetName())
+	name := appMeta.ValuesName(obj)
 	replicas, err := processReplicas(name, &depl, values)
 	if err != nil {
 		return true, nil, err
@@ -123,7 +152,12 @@ func (d deployment) Process(appMeta timonify.AppMetadata, obj *unstructured.Unst
 		return true, nil, err
 	}
 
-	matchLabels, err := cue.Marshal(depl.Spec.Selector.MatchLabels, 0, true)
+	strategy, err := processStrategy(name, &depl, values)
+	if err != nil {
+		return true, nil, err
+	}
+
+	matchLabels, err := processor.SelectorLabels(depl.Spec.Selector.MatchLabels)
 	if err != nil {
 		return true, nil, err
 	}
@@ -142,7 +176,7 @@ func (d deployment) Process(appMeta timonify.AppMetadata, obj *unstructured.Unst
 	selector = strings.Trim(selector, " \n")
 	selector = string(cue.Indent([]byte(selector), 4))
 
-	podLabels, err := cue.Marshal(depl.Spec.Template.ObjectMeta.Labels, 0, true)
+	podLabels, err := processor.SelectorLabels(depl.Spec.Template.ObjectMeta.Labels)
 	if err != nil {
 		return true, nil, err
 	}
@@ -158,7 +192,10 @@ func (d deployment) Process(appMeta timonify.AppMetadata, obj *unstructured.Unst
 	}
 
 	nameCamel := strcase.ToLowerCamel(name)
-	specMap, podValues, err := pod.ProcessSpec(nameCamel, appMeta, depl.Spec.Template.Spec)
+	// collect references before pod spec processing replaces names with templated ones.
+	configRefs := pod.ConfigRefs(depl.Spec.Template.Spec)
+	rawPodSpec, _, _ := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+	specMap, podValues, err := pod.ProcessSpec(nameCamel, appMeta, depl.Spec.Template.Spec, depl.Spec.Template.ObjectMeta.Labels, rawPodSpec)
 	if err != nil {
 		return true, nil, err
 	}
@@ -180,19 +217,25 @@ func (d deployment) Process(appMeta timonify.AppMetadata, obj *unstructured.Unst
 			Meta                 string
 			Replicas             string
 			RevisionHistoryLimit string
+			Strategy             string
 			Selector             string
 			PodLabels            string
 			PodAnnotations       string
 			Spec                 string
+			Checksums            string
+			ChecksumImports      string
 		}{
 			Meta:                 meta,
 			Replicas:             replicas,
 			RevisionHistoryLimit: revisionHistoryLimit,
+			Strategy:             strategy,
 			Selector:             selector,
 			PodLabels:            podLabels,
 			PodAnnotations:       podAnnotations,
 			Spec:                 spec,
 		},
+		appMeta:    appMeta,
+		configRefs: configRefs,
 	}, nil
 }
 
@@ -200,8 +243,7 @@ func processReplicas(name string, deployment *appsv1.Deployment, values *timonif
 	if deployment.Spec.Replicas == nil {
 		return "", nil
 	}
-	replicasSchema := cue.MustParse("*1 | int & >0")
-	replicasTpl, err := values.Add(replicasSchema, int64(*deployment.Spec.Replicas), name, "replicas")
+	replicasTpl, err := values.Add(cue.MustParse(processor.ReplicasSchema), int64(*deployment.Spec.Replicas), name, "replicas")
 	if err != nil {
 		return "", err
 	}
@@ -213,7 +255,7 @@ func processRevisionHistoryLimit(name string, deployment *appsv1.Deployment, val
 	if deployment.Spec.RevisionHistoryLimit == nil {
 		return "", nil
 	}
-	revisionHistoryLimitTpl, err := values.Add(ast.NewIdent("int64"), int64(*deployment.Spec.RevisionHistoryLimit), name, "revisionHistoryLimit")
+	revisionHistoryLimitTpl, err := values.Add(ast.NewIdent(processor.RevisionHistoryLimitSchema), int64(*deployment.Spec.RevisionHistoryLimit), name, "revisionHistoryLimit")
 	if err != nil {
 		return "", err
 	}
@@ -225,17 +267,66 @@ func processRevisionHistoryLimit(name string, deployment *appsv1.Deployment, val
 	return revisionHistoryLimit, nil
 }
 
+// processStrategy exposes update strategy, minReadySeconds and progressDeadlineSeconds as optional fields
+// in #config.<name>, values are added only for fields set in the deployment.
+func processStrategy(name string, deployment *appsv1.Deployment, values *timonify.Values) (string, error) {
+	_, err := values.AddOptionalConfig(cue.MustParse(strategySchema), name, "strategy")
+	if err != nil {
+		return "", err
+	}
+	if deployment.Spec.Strategy.Type != "" || deployment.Spec.Strategy.RollingUpdate != nil {
+		strategy, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment.Spec.Strategy)
+		if err != nil {
+			return "", fmt.Errorf("%w: unable to convert deployment strategy", err)
+		}
+		_, err = values.Add(nil, strategy, name, "strategy")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = values.AddOptionalConfig(cue.MustParse(minReadySecondsSchema), name, "minReadySeconds")
+	if err != nil {
+		return "", err
+	}
+	if deployment.Spec.MinReadySeconds != 0 {
+		_, err = values.Add(nil, int64(deployment.Spec.MinReadySeconds), name, "minReadySeconds")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = values.AddOptionalConfig(cue.MustParse(progressDeadlineSecondsSchema), name, "progressDeadlineSeconds")
+	if err != nil {
+		return "", err
+	}
+	if deployment.Spec.ProgressDeadlineSeconds != nil {
+		_, err = values.Add(nil, int64(*deployment.Spec.ProgressDeadlineSeconds), name, "progressDeadlineSeconds")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	res := fmt.Sprintf(strategyTempl, strcase.ToLowerCamel(name))
+	return string(cue.Indent([]byte(res), 4)), nil
+}
+
 type result struct {
 	data struct {
 		Meta                 string
 		Replicas             string
 		RevisionHistoryLimit string
+		Strategy             string
 		Selector             string
 		PodLabels            string
 		PodAnnotations       string
 		Spec                 string
+		Checksums            string
+		ChecksumImports      string
 	}
-	values *timonify.Values
+	values     *timonify.Values
+	appMeta    timonify.AppMetadata
+	configRefs []pod.ObjectRef
 }
 
 func (r *result) Filename() string {
@@ -247,8 +338,18 @@ func (r *result) Values() *timonify.Values {
 }
 
 func (r *result) Write(writer io.Writer) error {
+	// module ConfigMaps and Secrets are known only after all objects are processed.
+	data := r.data
+	checksums, err := pod.ChecksumAnnotations(r.appMeta, r.configRefs)
+	if err != nil {
+		return err
+	}
+	if checksums != "" {
+		data.Checksums = checksums
+		data.ChecksumImports = pod.ChecksumImports
+	}
 	var buf bytes.Buffer
-	if err := deploymentTempl.Execute(&buf, r.data); err != nil {
+	if err := deploymentTempl.Execute(&buf, data); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 	formatted, err := cueformat.Source(buf.Bytes())
@@ -1,8 +1,13 @@
 package deployment
 
 import (
+	"bytes"
 	"testing"
 
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/syndicut/timonify/pkg/config"
+
 	"github.com/syndicut/timonify/pkg/metadata"
 
 	"github.com/stretchr/testify/assert"
@@ -114,6 +119,30 @@ spec:
 `
 )
 
+const strDeplWithStrategy = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  minReadySeconds: 5
+  strategy:
+    type: RollingUpdate
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 0
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.25
+`
+
 func Test_deployment_Process(t *testing.T) {
 	var testInstance deployment
 
@@ -124,6 +153,46 @@ func Test_deployment_Process(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, true, processed)
 	})
+	t.Run("checksum annotations", func(t *testing.T) {
+		obj := internal.GenerateObj(strDepl)
+		appMeta := metadata.New(config.Config{})
+		appMeta.Load(obj)
+		_, tpl, err := testInstance.Process(appMeta, obj)
+		assert.NoError(t, err)
+		// referenced objects are registered by the app context after processing
+		appMeta.AddObjectType("Secret", "my-operator-secret-vars", ast.NewIdent("#SecretVars"))
+
+		var buf bytes.Buffer
+		err = tpl.Write(&buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `"encoding/json"`)
+		assert.Contains(t, buf.String(), `"checksum/secret-my-operator-secret-vars": hex.Encode(sha256.Sum256(json.Marshal({for k, v in (#SecretVars & {#config: #config})`)
+		assert.NotContains(t, buf.String(), "checksum/configmap")
+	})
+	t.Run("strategy", func(t *testing.T) {
+		obj := internal.GenerateObj(strDeplWithStrategy)
+		_, tpl, err := testInstance.Process(metadata.New(config.Config{}), obj)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"type": "\"RollingUpdate\"",
+			"rollingUpdate": map[string]interface{}{
+				"maxSurge":       "\"25%\"",
+				"maxUnavailable": int64(0),
+			},
+		}, tpl.Values().Values["web"].(map[string]interface{})["strategy"])
+		assert.Equal(t, int64(5), tpl.Values().Values["web"].(map[string]interface{})["minReadySeconds"])
+		assert.NotContains(t, tpl.Values().Values["web"], "progressDeadlineSeconds")
+
+		var buf bytes.Buffer
+		err = tpl.Write(&buf)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "if #config.web.strategy != _|_ {strategy: #config.web.strategy}")
+		assert.Contains(t, buf.String(), "if #config.web.progressDeadlineSeconds != _|_ {progressDeadlineSeconds: #config.web.progressDeadlineSeconds}")
+		cfg, err := format.Node(tpl.Values().Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "minReadySeconds?:         int & >=0")
+		assert.Contains(t, string(cfg), "progressDeadlineSeconds?: int & >(*minReadySeconds | 0)")
+	})
 	t.Run("skipped", func(t *testing.T) {
 		obj := internal.TestNs
 		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
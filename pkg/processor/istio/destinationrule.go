@@ -0,0 +1,61 @@
+package istio
+
+import (
+	"fmt"
+
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var destinationRuleGK = schema.GroupKind{
+	Group: "networking.istio.io",
+	Kind:  "DestinationRule",
+}
+
+// NewDestinationRule creates processor for Istio DestinationRule resource.
+func NewDestinationRule() timonify.Processor {
+	return &destinationRule{}
+}
+
+type destinationRule struct{}
+
+// Process Istio DestinationRule object into template. Returns false if not capable of processing given resource type.
+func (d destinationRule) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind().GroupKind() != destinationRuleGK {
+		return false, nil, nil
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get destination rule spec", err)
+	}
+	format.QuoteStringsInStruct(&spec)
+
+	values := timonify.NewValues()
+	name := appMeta.TrimName(obj.GetName())
+
+	if host, ok := spec["host"]; ok {
+		spec["host"] = templatedHost(appMeta, host)
+	}
+	// traffic policy is too diverse to type without Istio schemas
+	if trafficPolicy, ok := spec["trafficPolicy"]; ok {
+		ref, err := values.Add(cue.MustParse("{...}"), trafficPolicy, appMeta.ValuesName(obj), "destinationRule", "trafficPolicy")
+		if err != nil {
+			return true, nil, fmt.Errorf("%w: unable to set destination rule traffic policy", err)
+		}
+		spec["trafficPolicy"] = ref
+	}
+
+	res, err := newResult(name, "DestinationRule", meta, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, res, nil
+}
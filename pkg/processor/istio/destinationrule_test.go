@@ -0,0 +1,60 @@
+package istio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+)
+
+const strDestinationRule = `apiVersion: networking.istio.io/v1beta1
+kind: DestinationRule
+metadata:
+  name: my-app-reviews
+  namespace: my-ns
+spec:
+  host: my-app-reviews.my-ns.svc.cluster.local
+  trafficPolicy:
+    loadBalancer:
+      simple: LEAST_REQUEST
+  subsets:
+  - name: v1
+    labels:
+      version: v1
+`
+
+func Test_destinationRule_Process(t *testing.T) {
+	var testInstance destinationRule
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(strDestinationRule)
+		testMeta := metadata.New(config.Config{ModuleName: "module-name"})
+		testMeta.Load(obj)
+		testMeta.Load(internal.GenerateObj(strService))
+		testMeta.Load(internal.GenerateObj(strGateway))
+		processed, tpl, err := testInstance.Process(testMeta, obj)
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "#ReviewsDestinationRule: {")
+		assert.Contains(t, out, `host: "\(#config.metadata.name+"-reviews").\(#config.metadata.namespace).svc.\(#config.kubernetesClusterDomain)"`)
+		assert.Contains(t, out, "trafficPolicy: #config.reviews.destinationRule.trafficPolicy")
+		assert.Contains(t, out, `version: "v1"`)
+		assert.Equal(t, map[string]interface{}{
+			"loadBalancer": map[string]interface{}{"simple": `"LEAST_REQUEST"`},
+		}, tpl.Values().Values["reviews"].(map[string]interface{})["destinationRule"].(map[string]interface{})["trafficPolicy"])
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
@@ -0,0 +1,177 @@
+// Package istio contains processors for Istio traffic management and security resources.
+package istio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cluster"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+)
+
+// Istio schemas are not vendored into timoni modules, so objects are not typed.
+var istioTempl, _ = template.New("istio").Parse(
+	`package templates
+
+{{ .Type }}: {
+	#config:    #Config
+{{ .Meta }}
+	spec: {{ .Spec }}
+}`)
+
+// templatedHost rewrites quoted k8s service host into CUE expression. Service name is templated for module
+// services, app namespace is replaced with the instance namespace and cluster domain with the config value.
+// Supported host formats: name, name.namespace, name.namespace.svc and name.namespace.svc.cluster.local.
+func templatedHost(appMeta timonify.AppMetadata, quoted interface{}) interface{} {
+	str, ok := quoted.(string)
+	if !ok {
+		return quoted
+	}
+	host, err := strconv.Unquote(str)
+	if err != nil {
+		return quoted
+	}
+	domainSuffix := "." + cluster.DefaultDomain
+	hasDomain := strings.HasSuffix(host, domainSuffix)
+	parts := strings.Split(strings.TrimSuffix(host, domainSuffix), ".")
+	switch {
+	case len(parts) > 3, len(parts) == 3 && parts[2] != "svc", hasDomain && len(parts) != 3:
+		return quoted
+	}
+
+	var templated bool
+	segments := make([]string, 0, 4)
+	if name := appMeta.TemplatedName(parts[0]); name != parts[0] {
+		if len(parts) == 1 {
+			return name
+		}
+		segments = append(segments, `\(`+name+`)`)
+		templated = true
+	} else {
+		segments = append(segments, parts[0])
+	}
+	if len(parts) > 1 {
+		if ns := appMeta.Namespace(); ns != "" && parts[1] == ns {
+			segments = append(segments, `\(#config.metadata.namespace)`)
+			templated = true
+		} else {
+			segments = append(segments, parts[1])
+		}
+	}
+	if len(parts) > 2 {
+		segments = append(segments, parts[2])
+	}
+	if hasDomain {
+		segments = append(segments, `\(#config.`+cluster.DomainKey+`)`)
+		templated = true
+	}
+	if !templated {
+		return quoted
+	}
+	return `"` + strings.Join(segments, ".") + `"`
+}
+
+// templatedHosts rewrites each host of the list with templatedHost.
+func templatedHosts(appMeta timonify.AppMetadata, hosts interface{}) interface{} {
+	list, ok := hosts.([]interface{})
+	if !ok {
+		return hosts
+	}
+	for i, host := range list {
+		list[i] = templatedHost(appMeta, host)
+	}
+	return list
+}
+
+// templatedGateway rewrites gateway reference in format [namespace/]name.
+func templatedGateway(appMeta timonify.AppMetadata, quoted interface{}) interface{} {
+	str, ok := quoted.(string)
+	if !ok {
+		return quoted
+	}
+	gateway, err := strconv.Unquote(str)
+	if err != nil {
+		return quoted
+	}
+	ns, name, found := strings.Cut(gateway, "/")
+	if !found {
+		return processor.TemplatedQuotedName(appMeta, quoted)
+	}
+	if ns != appMeta.Namespace() {
+		return quoted
+	}
+	res := `\(#config.metadata.namespace)/` + name
+	if templated := appMeta.TemplatedName(name); templated != name {
+		res = `\(#config.metadata.namespace)/\(` + templated + `)`
+	}
+	return `"` + res + `"`
+}
+
+type result struct {
+	name string
+	kind string
+	data struct {
+		Type string
+		Meta string
+		Spec string
+	}
+	values *timonify.Values
+}
+
+func newResult(name, kind, meta string, spec map[string]interface{}, values *timonify.Values) (*result, error) {
+	specStr, err := cue.Marshal(spec, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{
+		name:   name,
+		kind:   kind,
+		values: values,
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	res.data.Spec = specStr
+	return res, nil
+}
+
+func (r *result) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + r.kind
+}
+
+func (r *result) Filename() string {
+	return r.name + "-" + strings.ToLower(r.kind) + ".cue"
+}
+
+func (r *result) Values() *timonify.Values {
+	return r.values
+}
+
+func (r *result) Write(writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := istioTempl.Execute(&buf, r.data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+func (r *result) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *result) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + r.kind)
+}
@@ -0,0 +1,84 @@
+package istio
+
+import (
+	"fmt"
+
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var peerAuthenticationGK = schema.GroupKind{
+	Group: "security.istio.io",
+	Kind:  "PeerAuthentication",
+}
+
+const mtlsModeSchema = `"UNSET" | "DISABLE" | "PERMISSIVE" | "STRICT"`
+
+// NewPeerAuthentication creates processor for Istio PeerAuthentication resource.
+func NewPeerAuthentication() timonify.Processor {
+	return &peerAuthentication{}
+}
+
+type peerAuthentication struct{}
+
+// Process Istio PeerAuthentication object into template. Returns false if not capable of processing given resource type.
+func (p peerAuthentication) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind().GroupKind() != peerAuthenticationGK {
+		return false, nil, nil
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get peer authentication spec", err)
+	}
+	format.QuoteStringsInStruct(&spec)
+
+	values := timonify.NewValues()
+	name := appMeta.TrimName(obj.GetName())
+	nameCamel := appMeta.ValuesName(obj)
+
+	if mtls, ok := spec["mtls"].(map[string]interface{}); ok {
+		if err = processMtlsMode(mtls, values, nameCamel, "peerAuthentication", "mtls", "mode"); err != nil {
+			return true, nil, err
+		}
+	}
+	if portLevelMtls, ok := spec["portLevelMtls"].(map[string]interface{}); ok {
+		for port, m := range portLevelMtls {
+			mtls, ok := m.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// port numbers are not valid CUE identifiers
+			err = processMtlsMode(mtls, values, nameCamel, "peerAuthentication", "portLevelMtls", "port"+port, "mode")
+			if err != nil {
+				return true, nil, err
+			}
+		}
+	}
+
+	res, err := newResult(name, "PeerAuthentication", meta, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, res, nil
+}
+
+func processMtlsMode(mtls map[string]interface{}, values *timonify.Values, name ...string) error {
+	mode, ok := mtls["mode"]
+	if !ok {
+		return nil
+	}
+	ref, err := values.Add(cue.MustParse(mtlsModeSchema), mode, name...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to set peer authentication mtls mode", err)
+	}
+	mtls["mode"] = ref
+	return nil
+}
@@ -0,0 +1,57 @@
+package istio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/metadata"
+)
+
+const strPeerAuthentication = `apiVersion: security.istio.io/v1beta1
+kind: PeerAuthentication
+metadata:
+  name: strict
+  namespace: my-ns
+spec:
+  selector:
+    matchLabels:
+      app: reviews
+  mtls:
+    mode: STRICT
+  portLevelMtls:
+    8080:
+      mode: DISABLE
+`
+
+func Test_peerAuthentication_Process(t *testing.T) {
+	var testInstance peerAuthentication
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(strPeerAuthentication)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "mode: #config.strict.peerAuthentication.mtls.mode")
+		assert.Contains(t, out, "mode: #config.strict.peerAuthentication.portLevelMtls.port8080.mode")
+
+		values := tpl.Values().Values["strict"].(map[string]interface{})["peerAuthentication"].(map[string]interface{})
+		assert.Equal(t, `"STRICT"`, values["mtls"].(map[string]interface{})["mode"])
+		cfg, err := format.Node(tpl.Values().Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), `mode: "UNSET" | "DISABLE" | "PERMISSIVE" | "STRICT"`)
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
@@ -0,0 +1,157 @@
+package istio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var virtualServiceGK = schema.GroupKind{
+	Group: "networking.istio.io",
+	Kind:  "VirtualService",
+}
+
+const (
+	weightSchema = `int & >=0 & <=100`
+	// retriesSchema - Istio HTTPRetry.
+	retriesSchema = `{
+	attempts?:              int & >=0
+	perTryTimeout?:         string
+	retryOn?:               string
+	retryRemoteLocalities?: bool
+}`
+)
+
+// NewVirtualService creates processor for Istio VirtualService resource.
+func NewVirtualService() timonify.Processor {
+	return &virtualService{}
+}
+
+type virtualService struct{}
+
+// Process Istio VirtualService object into template. Returns false if not capable of processing given resource type.
+func (v virtualService) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind().GroupKind() != virtualServiceGK {
+		return false, nil, nil
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get virtual service spec", err)
+	}
+	format.QuoteStringsInStruct(&spec)
+
+	values := timonify.NewValues()
+	name := appMeta.TrimName(obj.GetName())
+	nameCamel := appMeta.ValuesName(obj)
+
+	if hosts, ok := spec["hosts"]; ok {
+		spec["hosts"] = templatedHosts(appMeta, hosts)
+	}
+	if gateways, ok := spec["gateways"].([]interface{}); ok {
+		for i, gateway := range gateways {
+			gateways[i] = templatedGateway(appMeta, gateway)
+		}
+	}
+	for _, routeType := range []string{"http", "tls", "tcp"} {
+		routes, ok := spec[routeType].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, r := range routes {
+			route, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			err = processRoute(appMeta, route, values, nameCamel, "virtualService", routeType, routeKey(route, i))
+			if err != nil {
+				return true, nil, err
+			}
+		}
+	}
+
+	res, err := newResult(name, "VirtualService", meta, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+	return true, res, nil
+}
+
+// routeKey returns route name if set or its index otherwise.
+func routeKey(route map[string]interface{}, i int) string {
+	if quoted, ok := route["name"].(string); ok {
+		if name, err := strconv.Unquote(quoted); err == nil && name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("route%d", i)
+}
+
+// processRoute templates destination hosts and exposes destination weights, http timeout and retries in #Config.
+func processRoute(appMeta timonify.AppMetadata, route map[string]interface{}, values *timonify.Values, name ...string) error {
+	if destinations, ok := route["route"].([]interface{}); ok {
+		for i, d := range destinations {
+			destination, ok := d.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("destination%d", i)
+			if dst, ok := destination["destination"].(map[string]interface{}); ok {
+				key = destinationKey(dst, key)
+				dst["host"] = templatedHost(appMeta, dst["host"])
+			}
+			weight, ok := destination["weight"]
+			if !ok {
+				continue
+			}
+			ref, err := values.Add(cue.MustParse(weightSchema), weight, append(name, "weights", key)...)
+			if err != nil {
+				return fmt.Errorf("%w: unable to set virtual service weight", err)
+			}
+			destination["weight"] = ref
+		}
+	}
+	if mirror, ok := route["mirror"].(map[string]interface{}); ok {
+		mirror["host"] = templatedHost(appMeta, mirror["host"])
+	}
+	if timeout, ok := route["timeout"]; ok {
+		ref, err := values.Add(ast.NewIdent("string"), timeout, append(name, "timeout")...)
+		if err != nil {
+			return fmt.Errorf("%w: unable to set virtual service timeout", err)
+		}
+		route["timeout"] = ref
+	}
+	if retries, ok := route["retries"]; ok {
+		ref, err := values.Add(cue.MustParse(retriesSchema), retries, append(name, "retries")...)
+		if err != nil {
+			return fmt.Errorf("%w: unable to set virtual service retries", err)
+		}
+		route["retries"] = ref
+	}
+	return nil
+}
+
+// destinationKey returns destination subset or service name to identify destination weight.
+func destinationKey(destination map[string]interface{}, fallback string) string {
+	for _, key := range []string{"subset", "host"} {
+		quoted, ok := destination[key].(string)
+		if !ok {
+			continue
+		}
+		if val, err := strconv.Unquote(quoted); err == nil && val != "" {
+			return strings.Split(val, ".")[0]
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,109 @@
+package istio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+)
+
+const (
+	strVirtualService = `apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: my-app-reviews
+  namespace: my-ns
+spec:
+  hosts:
+  - my-app-reviews.my-ns.svc.cluster.local
+  gateways:
+  - my-app-gateway
+  - istio-system/public-gateway
+  http:
+  - name: canary
+    match:
+    - headers:
+        end-user:
+          exact: jason
+    route:
+    - destination:
+        host: my-app-reviews
+        subset: v1
+      weight: 80
+    - destination:
+        host: my-app-reviews.my-ns.svc.cluster.local
+        subset: v2
+      weight: 20
+    timeout: 5s
+    retries:
+      attempts: 3
+      perTryTimeout: 2s
+  - route:
+    - destination:
+        host: ratings.other-ns.svc.cluster.local
+`
+	strService = `apiVersion: v1
+kind: Service
+metadata:
+  name: my-app-reviews
+  namespace: my-ns
+spec:
+  ports:
+  - port: 9080
+`
+	strGateway = `apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: my-app-gateway
+  namespace: my-ns
+`
+)
+
+func Test_virtualService_Process(t *testing.T) {
+	var testInstance virtualService
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(strVirtualService)
+		testMeta := metadata.New(config.Config{ModuleName: "module-name"})
+		testMeta.Load(obj)
+		testMeta.Load(internal.GenerateObj(strService))
+		testMeta.Load(internal.GenerateObj(strGateway))
+		processed, tpl, err := testInstance.Process(testMeta, obj)
+		assert.NoError(t, err)
+		assert.True(t, processed)
+		assert.Equal(t, "reviews-virtualservice.cue", tpl.Filename())
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "#ReviewsVirtualService: {")
+		assert.Contains(t, out, `"\(#config.metadata.name+"-reviews").\(#config.metadata.namespace).svc.\(#config.kubernetesClusterDomain)"`)
+		assert.Contains(t, out, `host: #config.metadata.name + "-reviews"`)
+		assert.Contains(t, out, `host: "ratings.other-ns.svc.\(#config.kubernetesClusterDomain)"`)
+		assert.Contains(t, out, `#config.metadata.name + "-gateway"`)
+		assert.Contains(t, out, `"istio-system/public-gateway"`)
+		assert.Contains(t, out, "weight: #config.reviews.virtualService.http.canary.weights.v1")
+		assert.Contains(t, out, "weight: #config.reviews.virtualService.http.canary.weights.v2")
+		assert.Contains(t, out, "timeout: #config.reviews.virtualService.http.canary.timeout")
+		assert.Contains(t, out, "retries: #config.reviews.virtualService.http.canary.retries")
+
+		canary := tpl.Values().Values["reviews"].(map[string]interface{})["virtualService"].(map[string]interface{})["http"].(map[string]interface{})["canary"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"v1": int64(80), "v2": int64(20)}, canary["weights"])
+		assert.Equal(t, `"5s"`, canary["timeout"])
+		cfg, err := format.Node(tpl.Values().Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "v1: int & >=0 & <=100")
+		assert.Contains(t, string(cfg), "perTryTimeout?:")
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
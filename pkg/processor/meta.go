@@ -88,7 +88,7 @@ func ProcessObjMeta(appMeta timonify.AppMetadata, obj *unstructured.Unstructured
 
 	var metaStr string
 	if options.values.Values != nil && options.annotations {
-		name := strcase.ToLowerCamel(appMeta.TrimName(obj.GetName()))
+		name := appMeta.ValuesName(obj)
 		kind := strcase.ToLowerCamel(kind)
 		valuesAnnotations := make(map[string]interface{})
 		for k, v := range obj.GetAnnotations() {
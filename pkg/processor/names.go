@@ -0,0 +1,24 @@
+package processor
+
+import (
+	"strconv"
+
+	"github.com/syndicut/timonify/pkg/timonify"
+)
+
+// TemplatedQuotedName - returns templated name for quoted object name if the object is a part of the module.
+// Values which are not quoted strings or names of other objects are returned as is.
+func TemplatedQuotedName(appMeta timonify.AppMetadata, quoted interface{}) interface{} {
+	str, ok := quoted.(string)
+	if !ok {
+		return quoted
+	}
+	name, err := strconv.Unquote(str)
+	if err != nil {
+		return quoted
+	}
+	if templated := appMeta.TemplatedName(name); templated != name {
+		return templated
+	}
+	return quoted
+}
@@ -0,0 +1,205 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/syndicut/timonify/pkg/cluster"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// argsTemplate - rebuilds container args from leading positional args, flags and extra args.
+	// Flags are rendered in their original form, looked up by flag name in the separators struct: "=" for --key=value,
+	// " " for --key value. Other flags, e.g. bare bool flags or flags added to #config, are rendered as --key if true,
+	// omitted if false and rendered as --key=value otherwise.
+	argsTemplate = `[%[1]sfor k, v in #config.%[2]s.%[3]s.flags let sep = *%[4]s[k] | "" for arg in [
+	if sep == "" && (v & true) != _|_ {"--\(k)"},
+	if sep == "" && (v & bool) == _|_ {"--\(k)=\(v)"},
+	if sep == "=" {"--\(k)=\(v)"},
+	if sep == " " {"--\(k)"},
+	if sep == " " {"\(v)"},
+] {arg}, for v in #config.%[2]s.%[3]s.extraArgs {v}]`
+	flagSchema      = "bool | int | string"
+	extraArgsSchema = "[...string]"
+)
+
+// containerArgs - container args split into structured flags.
+type containerArgs struct {
+	// leading - args before the first flag, e.g. sub-commands, kept in place.
+	leading []string
+	// flagNames - flag names in original order.
+	flagNames []string
+	flags     map[string]interface{}
+	// separators - separators of flag names and values, "=" or " ", by flag name, empty for bare bool flags.
+	separators map[string]string
+	// extra - args after the flags, kept as is.
+	extra []string
+}
+
+// parseArgs parses a run of --key=value, --key value and bare --key bool flags, args are quoted CUE strings. Args
+// before the run are kept in leading args and args after it in extra args, so args keep their original order.
+// The run ends at positional and single dash args, "--", repeated flags and flags with values referencing
+// the cluster domain.
+func parseArgs(args []string) containerArgs {
+	res := containerArgs{flags: map[string]interface{}{}, separators: map[string]string{}}
+	for i := 0; i < len(args); i++ {
+		name, value, sep, ok := parseFlag(args, i)
+		if _, exists := res.flags[name]; !ok || exists {
+			if len(res.flagNames) == 0 {
+				res.leading = append(res.leading, args[i])
+				continue
+			}
+			res.extra = append(res.extra, args[i:]...)
+			break
+		}
+		res.flagNames = append(res.flagNames, name)
+		res.flags[name] = value
+		if sep != "" {
+			res.separators[name] = sep
+		}
+		if sep == " " {
+			i++
+		}
+	}
+	return res
+}
+
+// parseFlag parses i-th arg as --key=value, --key value or bare --key bool flag and returns flag name, value
+// and separator of the name and the value. Bare --key followed by an arg which is not a flag is parsed as --key value.
+func parseFlag(args []string, i int) (string, interface{}, string, bool) {
+	arg := unquote(args[i])
+	if !strings.HasPrefix(arg, "--") {
+		return "", nil, "", false
+	}
+	name, value, hasValue := strings.Cut(arg[2:], "=")
+	switch {
+	case name == "":
+		return "", nil, "", false
+	case hasValue && cluster.References(value):
+		return "", nil, "", false
+	case hasValue:
+		return name, flagValue(value), "=", true
+	case i+1 < len(args) && !isFlag(unquote(args[i+1])):
+		value = unquote(args[i+1])
+		if cluster.References(value) {
+			return "", nil, "", false
+		}
+		return name, flagValue(value), " ", true
+	}
+	return name, true, "", true
+}
+
+// isFlag returns true if arg starts with a dash and is not a negative number.
+func isFlag(arg string) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+// flagValue infers flag value type, strings are quoted for CUE.
+func flagValue(value string) interface{} {
+	if b, err := strconv.ParseBool(value); err == nil && (value == "true" || value == "false") {
+		return b
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(i, 10) == value {
+		return i
+	}
+	return strconv.Quote(value)
+}
+
+// referencesDomain returns true if any of quoted args references the default cluster domain.
+func referencesDomain(args []string) bool {
+	for _, arg := range args {
+		if cluster.References(unquote(arg)) {
+			return true
+		}
+	}
+	return false
+}
+
+// templatedArgs interpolates #config.kubernetesClusterDomain into quoted args referencing the default cluster domain.
+func templatedArgs(args []string) []string {
+	res := make([]string, 0, len(args))
+	for _, arg := range args {
+		templated, _ := cluster.Templated(arg)
+		res = append(res, templated)
+	}
+	return res
+}
+
+func unquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// processArgs exposes container args as typed flags in #config.<objName>.<containerName>.flags, args which can not
+// be parsed as flags are exposed in #config.<objName>.<containerName>.extraArgs. If args have no flags, the whole
+// list is exposed in #config.<objName>.<containerName>.args.
+func processArgs(objName, containerName string, container map[string]interface{}, values *timonify.Values) error {
+	args, exists, err := unstructured.NestedStringSlice(container, "args")
+	if err != nil || !exists || len(args) == 0 {
+		return err
+	}
+	parsed := parseArgs(args)
+	if len(parsed.flags) == 0 || referencesDomain(parsed.extra) {
+		if referencesDomain(args) {
+			// args depend on the cluster domain, so they are kept in the template
+			container["args"] = "[" + strings.Join(templatedArgs(args), ", ") + "]"
+			return nil
+		}
+		_, err = values.Add(cueformat.MustParse(extraArgsSchema), args, objName, containerName, "args")
+		if err != nil {
+			return fmt.Errorf("%w: unable to set container args", err)
+		}
+		container["args"] = fmt.Sprintf(`#config.%[1]s.%[2]s.args`, objName, containerName)
+		return nil
+	}
+
+	var schema strings.Builder
+	schema.WriteString("{\n")
+	for _, name := range parsed.flagNames {
+		typ := "string"
+		switch parsed.flags[name].(type) {
+		case bool:
+			typ = "bool"
+		case int64:
+			typ = "int"
+		}
+		fmt.Fprintf(&schema, "\t%s: %s\n", strconv.Quote(name), typ)
+	}
+	fmt.Fprintf(&schema, "\t[string]: %s\n}", flagSchema)
+	_, err = values.Add(cueformat.MustParse(schema.String()), parsed.flags, objName, containerName, "flags")
+	if err != nil {
+		return fmt.Errorf("%w: unable to set container flags", err)
+	}
+
+	extra := make([]interface{}, 0, len(parsed.extra))
+	for _, arg := range parsed.extra {
+		extra = append(extra, arg)
+	}
+	_, err = values.Add(cueformat.MustParse(extraArgsSchema), extra, objName, containerName, "extraArgs")
+	if err != nil {
+		return fmt.Errorf("%w: unable to set container extra args", err)
+	}
+
+	leading := ""
+	for _, arg := range templatedArgs(parsed.leading) {
+		leading += arg + ", "
+	}
+	separators := make([]string, 0, len(parsed.separators))
+	for _, name := range parsed.flagNames {
+		if sep, ok := parsed.separators[name]; ok {
+			separators = append(separators, strconv.Quote(name)+": "+strconv.Quote(sep))
+		}
+	}
+	container["args"] = fmt.Sprintf(argsTemplate, leading, objName, containerName, "{"+strings.Join(separators, ", ")+"}")
+	return nil
+}
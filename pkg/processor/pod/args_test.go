@@ -0,0 +1,114 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/pkg/timonify"
+)
+
+func Test_parseArgs(t *testing.T) {
+	parsed := parseArgs([]string{
+		`"serve"`, `"--leader-elect"`, `"--metrics-bind-address=127.0.0.1:8080"`, `"--enable-x=false"`,
+		`"--mode=0755"`, `"--offset=-5"`, `"--v"`, `"10"`, `"--leader-elect"`, `"-q"`, `"--"`, `"--raw"`,
+	})
+	assert.Equal(t, []string{`"serve"`}, parsed.leading)
+	assert.Equal(t, []string{"leader-elect", "metrics-bind-address", "enable-x", "mode", "offset", "v"}, parsed.flagNames)
+	assert.Equal(t, map[string]interface{}{
+		"leader-elect":         true,
+		"metrics-bind-address": `"127.0.0.1:8080"`,
+		"enable-x":             false,
+		"mode":                 `"0755"`,
+		"offset":               int64(-5),
+		"v":                    int64(10),
+	}, parsed.flags)
+	assert.Equal(t, map[string]string{
+		"metrics-bind-address": "=",
+		"enable-x":             "=",
+		"mode":                 "=",
+		"offset":               "=",
+		"v":                    " ",
+	}, parsed.separators)
+	assert.Equal(t, []string{`"--leader-elect"`, `"-q"`, `"--"`, `"--raw"`}, parsed.extra)
+
+	t.Run("separate values", func(t *testing.T) {
+		parsed := parseArgs([]string{`"--config"`, `"config.yaml"`, `"--offset"`, `"-5"`, `"--in"`, `"-"`, `"--debug"`, `"-q"`})
+		assert.Nil(t, parsed.leading)
+		assert.Equal(t, map[string]interface{}{
+			"config": `"config.yaml"`,
+			"offset": int64(-5),
+			"in":     `"-"`,
+			"debug":  true,
+		}, parsed.flags)
+		assert.Equal(t, map[string]string{"config": " ", "offset": " ", "in": " "}, parsed.separators)
+		assert.Equal(t, []string{`"-q"`}, parsed.extra)
+	})
+
+	t.Run("positional args are kept in order", func(t *testing.T) {
+		parsed := parseArgs([]string{`"run"`, `"--port=80"`, `"main.go"`, `"--verbose"`})
+		assert.Equal(t, []string{`"run"`}, parsed.leading)
+		assert.Equal(t, map[string]interface{}{"port": int64(80)}, parsed.flags)
+		assert.Equal(t, []string{`"main.go"`, `"--verbose"`}, parsed.extra)
+	})
+}
+
+func Test_processArgs(t *testing.T) {
+	t.Run("flags", func(t *testing.T) {
+		container := map[string]interface{}{
+			"args": []interface{}{`"run"`, `"--port=8080"`, `"--debug=false"`, `"--v"`, `"2"`, `"--leader-elect"`},
+		}
+		values := timonify.NewValues()
+		err := processArgs("app", "web", container, values)
+		assert.NoError(t, err)
+		assert.Equal(t, `["run", for k, v in #config.app.web.flags let sep = *{"port": "=", "debug": "=", "v": " "}[k] | "" for arg in [
+	if sep == "" && (v & true) != _|_ {"--\(k)"},
+	if sep == "" && (v & bool) == _|_ {"--\(k)=\(v)"},
+	if sep == "=" {"--\(k)=\(v)"},
+	if sep == " " {"--\(k)"},
+	if sep == " " {"\(v)"},
+] {arg}, for v in #config.app.web.extraArgs {v}]`, container["args"])
+		assert.Equal(t, map[string]interface{}{
+			"app": map[string]interface{}{
+				"web": map[string]interface{}{
+					"flags":     map[string]interface{}{"port": int64(8080), "debug": false, "v": int64(2), "leader-elect": true},
+					"extraArgs": []interface{}{},
+				},
+			},
+		}, values.Values)
+	})
+	t.Run("no flags", func(t *testing.T) {
+		container := map[string]interface{}{
+			"args": []interface{}{`"run"`, `"-q"`},
+		}
+		values := timonify.NewValues()
+		err := processArgs("app", "web", container, values)
+		assert.NoError(t, err)
+		assert.Equal(t, "#config.app.web.args", container["args"])
+		assert.Equal(t, map[string]interface{}{
+			"app": map[string]interface{}{
+				"web": map[string]interface{}{
+					"args": []interface{}{`"run"`, `"-q"`},
+				},
+			},
+		}, values.Values)
+	})
+	t.Run("cluster domain", func(t *testing.T) {
+		container := map[string]interface{}{
+			"args": []interface{}{`"run"`, `"--upstream=api.default.svc.cluster.local"`, `"--port=8080"`},
+		}
+		values := timonify.NewValues()
+		err := processArgs("app", "web", container, values)
+		assert.NoError(t, err)
+		assert.Contains(t, container["args"], `["run", "--upstream=api.default.svc.\(#config.kubernetesClusterDomain)", for k, v in`)
+		assert.Equal(t, map[string]interface{}{"port": int64(8080)}, values.Values["app"].(map[string]interface{})["web"].(map[string]interface{})["flags"])
+
+		container = map[string]interface{}{
+			"args": []interface{}{`"-host"`, `"db.svc.cluster.local"`},
+		}
+		values = timonify.NewValues()
+		err = processArgs("app", "web", container, values)
+		assert.NoError(t, err)
+		assert.Equal(t, `["-host", "db.svc.\(#config.kubernetesClusterDomain)"]`, container["args"])
+		assert.Empty(t, values.Values)
+	})
+}
@@ -0,0 +1,117 @@
+package pod
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/syndicut/timonify/pkg/timonify"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// checksumTemplate - hash of data fields of the rendered module object, so pods roll when the object data changes.
+const checksumTemplate = `"checksum/%[1]s-%[2]s": hex.Encode(sha256.Sum256(json.Marshal({for k, v in (%[3]s & {#config: #config}) if k == "data" || k == "binaryData" || k == "stringData" {(k): v}})))`
+
+// ChecksumImports - CUE imports required by checksum annotations.
+const ChecksumImports = `	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"`
+
+// ObjectRef - reference from pod spec to ConfigMap or Secret.
+type ObjectRef struct {
+	Kind string
+	Name string
+}
+
+// ConfigRefs returns ConfigMaps and Secrets referenced by pod spec volumes and container environment.
+// Spec strings may be already quoted for CUE.
+func ConfigRefs(spec corev1.PodSpec) []ObjectRef {
+	refs := map[ObjectRef]struct{}{}
+	add := func(kind, name string) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		if name != "" {
+			refs[ObjectRef{Kind: kind, Name: name}] = struct{}{}
+		}
+	}
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			add("ConfigMap", v.ConfigMap.Name)
+		}
+		if v.Secret != nil {
+			add("Secret", v.Secret.SecretName)
+		}
+		if v.Projected == nil {
+			continue
+		}
+		for _, s := range v.Projected.Sources {
+			if s.ConfigMap != nil {
+				add("ConfigMap", s.ConfigMap.Name)
+			}
+			if s.Secret != nil {
+				add("Secret", s.Secret.Name)
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, e := range c.EnvFrom {
+			if e.ConfigMapRef != nil {
+				add("ConfigMap", e.ConfigMapRef.Name)
+			}
+			if e.SecretRef != nil {
+				add("Secret", e.SecretRef.Name)
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if e.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", e.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if e.ValueFrom.SecretKeyRef != nil {
+				add("Secret", e.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	res := make([]ObjectRef, 0, len(refs))
+	for ref := range refs {
+		res = append(res, ref)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind < res[j].Kind
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// ChecksumAnnotations returns pod template annotations with checksums of referenced module ConfigMaps and Secrets.
+// Refs to objects which are not part of the module are skipped. Object types are registered during processing,
+// so annotations should be rendered on template Write. Returns empty string if there is nothing to checksum.
+// Used by workloads rolling their pods on pod template changes (Deployment, Rollout). ReplicaSets and bare Pods are
+// excluded on purpose: a ReplicaSet does not replace running pods when its template changes and pod annotation
+// updates do not restart containers, so checksums would only add noise there.
+func ChecksumAnnotations(appMeta timonify.AppMetadata, refs []ObjectRef) (string, error) {
+	var checksums []string
+	for _, ref := range refs {
+		objType, ok := appMeta.ObjectType(ref.Kind, ref.Name)
+		if !ok {
+			continue
+		}
+		typeName, err := cueformat.Node(objType)
+		if err != nil {
+			return "", fmt.Errorf("%w: unable to format %s %s type", err, ref.Kind, ref.Name)
+		}
+		checksums = append(checksums, fmt.Sprintf(checksumTemplate, strings.ToLower(ref.Kind), appMeta.TrimName(ref.Name), typeName))
+	}
+	if len(checksums) == 0 {
+		return "", nil
+	}
+	return "\nannotations: {\n" + strings.Join(checksums, "\n") + "\n}", nil
+}
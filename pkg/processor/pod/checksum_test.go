@@ -0,0 +1,64 @@
+package pod
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConfigRefs(t *testing.T) {
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "cfg", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: `"app-config"`}},
+			}},
+			{Name: "tls", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "app-tls"},
+			}},
+		},
+		Containers: []corev1.Container{{
+			Name: "app",
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}},
+			},
+			Env: []corev1.EnvVar{{
+				Name: "PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "app-creds"},
+					Key:                  "password",
+				}},
+			}},
+		}},
+	}
+	assert.Equal(t, []ObjectRef{
+		{Kind: "ConfigMap", Name: "app-config"},
+		{Kind: "Secret", Name: "app-creds"},
+		{Kind: "Secret", Name: "app-tls"},
+	}, ConfigRefs(spec))
+}
+
+func TestChecksumAnnotations(t *testing.T) {
+	appMeta := metadata.New(config.Config{})
+	appMeta.AddObjectType("ConfigMap", "app-config", ast.NewIdent("#AppConfig"))
+
+	t.Run("module objects", func(t *testing.T) {
+		annotations, err := ChecksumAnnotations(appMeta, []ObjectRef{
+			{Kind: "ConfigMap", Name: "app-config"},
+			{Kind: "Secret", Name: "external"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `
+annotations: {
+"checksum/configmap-app-config": hex.Encode(sha256.Sum256(json.Marshal({for k, v in (#AppConfig & {#config: #config}) if k == "data" || k == "binaryData" || k == "stringData" {(k): v}})))
+}`, annotations)
+	})
+	t.Run("no module objects", func(t *testing.T) {
+		annotations, err := ChecksumAnnotations(appMeta, []ObjectRef{{Kind: "Secret", Name: "external"}})
+		assert.NoError(t, err)
+		assert.Empty(t, annotations)
+	})
+}
@@ -6,9 +6,10 @@ import (
 	"strings"
 
 	"cuelang.org/go/cue/ast"
-	"github.com/iancoleman/strcase"
+	"github.com/sirupsen/logrus"
 	"github.com/syndicut/timonify/pkg/cluster"
 	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/processor"
 	securityContext "github.com/syndicut/timonify/pkg/processor/security-context"
 	"github.com/syndicut/timonify/pkg/timonify"
 	corev1 "k8s.io/api/core/v1"
@@ -19,7 +20,77 @@ import (
 const imagePullPolicyTemplate = "#config.%[1]s.%[2]s.imagePullPolicy"
 const envValue = "#config.%[1]s.%[2]s.%[3]s.%[4]s"
 
-func ProcessSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSpec) (map[string]interface{}, *timonify.Values, error) {
+const (
+	probeEnabledTemplate = "#config.%[1]s.%[2]s.probes.%[3]s.enabled"
+	// probeTemplate - probe config without enabled toggle, which is not a part of corev1.#Probe.
+	probeTemplate = `{for k, v in #config.%[1]s.%[2]s.probes.%[3]s if k != "enabled" {(k): v}}`
+	probeSchema   = `{
+	enabled: *true | bool
+	corev1.#Probe
+%s}`
+	// probePortSchema - probe handler port linked to the container port, ports is a sibling of probes in #Config.
+	probePortSchema = "\t%[1]s: port: *ports.%[2]s | int\n"
+	portTemplate    = "#config.%[1]s.%[2]s.ports.%[3]s"
+	portSchema      = "int & >0 & <=65535"
+)
+
+const (
+	imagePullSecretsSchema  = "[...corev1.#LocalObjectReference]"
+	extraVolumesSchema      = "[...corev1.#Volume]"
+	extraVolumeMountsSchema = "[...corev1.#VolumeMount]"
+)
+
+// placementFields - pod scheduling fields exposed in #Config with -generate-defaults.
+var placementFields = []struct {
+	field  string
+	schema string
+}{
+	{field: "nodeSelector", schema: "{[string]: string}"},
+	{field: "tolerations", schema: "[...corev1.#Toleration]"},
+	{field: "affinity", schema: "corev1.#Affinity"},
+	{field: "topologySpreadConstraints", schema: "[...corev1.#TopologySpreadConstraint]"},
+	{field: "priorityClassName", schema: "string"},
+}
+
+// probeHandlers - probe handlers with port field.
+var probeHandlers = []string{"httpGet", "tcpSocket", "grpc"}
+
+// probes - container probe fields mapped to their names in #Config.
+var probes = []struct {
+	field string
+	name  string
+}{
+	{field: "livenessProbe", name: "liveness"},
+	{field: "readinessProbe", name: "readiness"},
+	{field: "startupProbe", name: "startup"},
+}
+
+// ProcessSpec - processes pod spec of the object with given pod labels, container ports are registered in
+// appMeta to let services reference them. rawSpec is the original unstructured pod spec, it is used for fields
+// which are not known to corev1 types, e.g. restartPolicy of native sidecar init containers, and may be nil.
+// Ephemeral containers are kept as is.
+func ProcessSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSpec, podLabels map[string]string, rawSpec map[string]interface{}) (map[string]interface{}, *timonify.Values, error) {
+	specMap, values, err := processSpec(objName, appMeta, spec, podLabels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// containers lists are rendered as CUE expressions, so they are processed last.
+	err = processExtraContainers(objName, specMap, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = processSidecars(objName, sidecarNames(rawSpec), specMap, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return specMap, values, nil
+}
+
+// processSpec - processes pod spec fields, containers lists are left as maps to be rendered by ProcessSpec.
+func processSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSpec, podLabels map[string]string) (map[string]interface{}, *timonify.Values, error) {
 	values, err := processPodSpec(objName, appMeta, &spec)
 	if err != nil {
 		return nil, nil, err
@@ -31,9 +102,9 @@ func ProcessSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSp
 		if vol.PersistentVolumeClaim == nil {
 			continue
 		}
-		tempPVCName := appMeta.TemplatedName(vol.PersistentVolumeClaim.ClaimName)
+		tempPVCName := processor.TemplatedQuotedName(appMeta, vol.PersistentVolumeClaim.ClaimName)
 
-		spec.Volumes[i].PersistentVolumeClaim.ClaimName = tempPVCName
+		spec.Volumes[i].PersistentVolumeClaim.ClaimName = tempPVCName.(string)
 	}
 
 	// replace container resources with template to values.
@@ -41,6 +112,20 @@ func ProcessSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSp
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: unable to convert podSpec to map", err)
 	}
+	for key, names := range timonify.ContainerKeys(specMap).Clashes() {
+		logrus.Warnf("containers %q of %q normalize to the same value key %q, keeping their names quoted", names, objName, key)
+	}
+
+	ports, err := processPorts(objName, specMap, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	appMeta.AddContainerPorts(unquoteMap(podLabels), ports)
+
+	err = processVolumes(objName, specMap, values)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	specMap, values, err = processNestedContainers(specMap, objName, values, "containers")
 	if err != nil {
@@ -53,29 +138,34 @@ func ProcessSpec(objName string, appMeta timonify.AppMetadata, spec corev1.PodSp
 	}
 
 	if appMeta.Config().ImagePullSecrets {
-		if _, defined := specMap["imagePullSecrets"]; !defined {
-			specMap["imagePullSecrets"] = "{{ .Values.imagePullSecrets | default list | toJson }}"
-			if _, err := values.Add(ast.NewSel(ast.NewIdent("corev1"), "#LocalObjectReference"), []string{}, "imagePullSecrets"); err != nil {
-				return nil, nil, err
-			}
+		err = processImagePullSecrets(specMap, values)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	err = securityContext.ProcessContainerSecurityContext(objName, specMap, values)
+	err = securityContext.ProcessPodSecurityContext(objName, appMeta.Config().PodSecurity, specMap, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = securityContext.ProcessContainerSecurityContext(objName, appMeta.Config().PodSecurity, specMap, values)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// process nodeSelector if presented:
-	if spec.NodeSelector != nil {
+	if appMeta.Config().GenerateDefaults {
+		err = processPlacement(objName, specMap, values)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if spec.NodeSelector != nil {
+		// process nodeSelector if presented:
 		err = unstructured.SetNestedField(specMap, fmt.Sprintf(`#config.%s.nodeSelector`, objName), "nodeSelector")
 		if err != nil {
 			return nil, nil, err
 		}
-		_, err = values.Add(ast.NewStruct(&ast.Field{
-			Label: ast.NewList(ast.NewIdent("string")),
-			Value: ast.NewIdent("string"),
-		}), spec.NodeSelector, objName, "nodeSelector")
+		_, err = values.Add(cueformat.MustParse(placementFields[0].schema), spec.NodeSelector, objName, "nodeSelector")
 		if err != nil {
 			return nil, nil, err
 		}
@@ -91,7 +181,7 @@ func processNestedContainers(specMap map[string]interface{}, objName string, val
 	}
 
 	if len(containers) > 0 {
-		containers, values, err = processContainers(objName, *values, containerKey, containers)
+		containers, values, err = processContainers(objName, *values, timonify.ContainerKeys(specMap), containers)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -105,10 +195,10 @@ func processNestedContainers(specMap map[string]interface{}, objName string, val
 	return specMap, values, nil
 }
 
-func processContainers(objName string, values timonify.Values, containerType string, containers []interface{}) ([]interface{}, *timonify.Values, error) {
+func processContainers(objName string, values timonify.Values, keys timonify.NameKeys, containers []interface{}) ([]interface{}, *timonify.Values, error) {
 	for i := range containers {
-		containerName := strcase.ToLowerCamel((containers[i].(map[string]interface{})["name"]).(string))
-		res, exists, err := unstructured.NestedMap(values.Values, objName, containerName, "resources")
+		containerName := keys.ContainerKey(containers[i].(map[string]interface{}))
+		res, exists, err := unstructured.NestedMap(values.Values, objName, timonify.LabelName(containerName), "resources")
 		if err != nil {
 			return nil, nil, err
 		}
@@ -119,29 +209,282 @@ func processContainers(objName string, values timonify.Values, containerType str
 			}
 		}
 
-		args, exists, err := unstructured.NestedStringSlice(containers[i].(map[string]interface{}), "args")
+		err = processArgs(objName, containerName, containers[i].(map[string]interface{}), &values)
 		if err != nil {
 			return nil, nil, err
 		}
-		if exists && len(args) > 0 {
-			err = unstructured.SetNestedField(containers[i].(map[string]interface{}), fmt.Sprintf(`#config.%[1]s.%[2]s.args`, objName, containerName), "args")
+
+		err = processProbes(objName, containerName, containers[i].(map[string]interface{}), &values)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return containers, &values, nil
+}
+
+// processImagePullSecrets appends module-wide #config.imagePullSecrets to the pod image pull secrets.
+func processImagePullSecrets(specMap map[string]interface{}, values *timonify.Values) error {
+	ref, err := values.Add(cueformat.MustParse(imagePullSecretsSchema), []interface{}{}, "imagePullSecrets")
+	if err != nil {
+		return fmt.Errorf("%w: unable to set image pull secrets", err)
+	}
+	return appendList(specMap, "imagePullSecrets", ref)
+}
+
+// appendList appends list referenced by ref to the obj list field, the field is replaced with ref if it is empty.
+func appendList(obj map[string]interface{}, field, ref string) error {
+	list, _, err := unstructured.NestedSlice(obj, field)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		obj[field] = ref
+		return nil
+	}
+	elems := make([]string, 0, len(list)+1)
+	for _, elem := range list {
+		marshalled, err := cueformat.Marshal(elem, 0, true)
+		if err != nil {
+			return err
+		}
+		elems = append(elems, marshalled)
+	}
+	elems = append(elems, fmt.Sprintf("for v in %s {v}", ref))
+	obj[field] = "[" + strings.Join(elems, ", ") + "]"
+	return nil
+}
+
+// processVolumes exposes emptyDir size limits and hostPath paths in #config.<objName>.volumes.<volumeName>
+// and appends user defined #config.<objName>.extraVolumes and #config.<objName>.<containerName>.extraVolumeMounts.
+func processVolumes(objName string, specMap map[string]interface{}, values *timonify.Values) error {
+	volumes, _, err := unstructured.NestedSlice(specMap, "volumes")
+	if err != nil {
+		return err
+	}
+	volumeNames := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		volumeNames = append(volumeNames, unquote(fmt.Sprint(v.(map[string]interface{})["name"])))
+	}
+	volumeKeys := timonify.NewNameKeys(volumeNames...)
+	for _, v := range volumes {
+		volume := v.(map[string]interface{})
+		volumeName := volumeKeys.Key(unquote(fmt.Sprint(volume["name"])))
+		if sizeLimit, ok, _ := unstructured.NestedString(volume, "emptyDir", "sizeLimit"); ok {
+			// quantities are not quoted with the rest of the spec
+			ref, err := values.Add(ast.NewIdent("string"), strconv.Quote(sizeLimit), objName, "volumes", volumeName, "sizeLimit")
 			if err != nil {
-				return nil, nil, err
+				return fmt.Errorf("%w: unable to set emptyDir size limit", err)
 			}
+			_ = unstructured.SetNestedField(volume, ref, "emptyDir", "sizeLimit")
+		}
+		if path, ok, _ := unstructured.NestedString(volume, "hostPath", "path"); ok {
+			ref, err := values.Add(ast.NewIdent("string"), path, objName, "volumes", volumeName, "hostPath")
+			if err != nil {
+				return fmt.Errorf("%w: unable to set hostPath path", err)
+			}
+			_ = unstructured.SetNestedField(volume, ref, "hostPath", "path")
+		}
+	}
+	if len(volumes) != 0 {
+		if err = unstructured.SetNestedSlice(specMap, volumes, "volumes"); err != nil {
+			return err
+		}
+	}
+	ref, err := values.Add(cueformat.MustParse(extraVolumesSchema), []interface{}{}, objName, "extraVolumes")
+	if err != nil {
+		return fmt.Errorf("%w: unable to set extra volumes", err)
+	}
+	if err = appendList(specMap, "volumes", ref); err != nil {
+		return err
+	}
 
-			_, err = values.Add(ast.NewList(&ast.Ellipsis{Type: ast.NewIdent("string")}), args, objName, containerName, "args")
+	keys := timonify.ContainerKeys(specMap)
+	for _, containerKey := range []string{"containers", "initContainers"} {
+		containers, _, err := unstructured.NestedSlice(specMap, containerKey)
+		if err != nil {
+			return err
+		}
+		for _, c := range containers {
+			container := c.(map[string]interface{})
+			containerName := keys.ContainerKey(container)
+			ref, err := values.Add(cueformat.MustParse(extraVolumeMountsSchema), []interface{}{}, objName, containerName, "extraVolumeMounts")
 			if err != nil {
-				return nil, nil, fmt.Errorf("%w: unable to set deployment value field", err)
+				return fmt.Errorf("%w: unable to set extra volume mounts", err)
+			}
+			if err = appendList(container, "volumeMounts", ref); err != nil {
+				return err
+			}
+		}
+		if len(containers) != 0 {
+			if err = unstructured.SetNestedSlice(specMap, containers, containerKey); err != nil {
+				return err
 			}
 		}
 	}
-	return containers, &values, nil
+	return nil
+}
+
+// processPlacement exposes pod scheduling fields in #config.<objName>. Fields defined in the spec become
+// config values, others are added as optional config fields and rendered only if set.
+func processPlacement(objName string, specMap map[string]interface{}, values *timonify.Values) error {
+	for _, f := range placementFields {
+		schema := cueformat.MustParse(f.schema)
+		if value, ok := specMap[f.field]; ok {
+			ref, err := values.Add(schema, value, objName, f.field)
+			if err != nil {
+				return fmt.Errorf("%w: unable to set pod %s", err, f.field)
+			}
+			specMap[f.field] = ref
+			continue
+		}
+		ref, err := values.AddOptionalConfig(schema, objName, f.field)
+		if err != nil {
+			return fmt.Errorf("%w: unable to set pod %s", err, f.field)
+		}
+		specMap[cueformat.If(ref+" != _|_")] = map[string]interface{}{f.field: ref}
+	}
+	return nil
+}
+
+// processPorts exposes containers ports in #config.<objName>.<containerName>.ports keyed by port name
+// or by port number for unnamed ports.
+func processPorts(objName string, specMap map[string]interface{}, values *timonify.Values) ([]timonify.ContainerPort, error) {
+	var res []timonify.ContainerPort
+	keys := timonify.ContainerKeys(specMap)
+	for _, containerKey := range []string{"containers", "initContainers"} {
+		containers, _, err := unstructured.NestedSlice(specMap, containerKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			container := c.(map[string]interface{})
+			containerName := keys.ContainerKey(container)
+			ports, _, err := unstructured.NestedSlice(container, "ports")
+			if err != nil {
+				return nil, err
+			}
+			portKeys := containerPortKeys(ports)
+			for _, p := range ports {
+				port := p.(map[string]interface{})
+				number, ok := port["containerPort"].(int64)
+				if !ok {
+					continue
+				}
+				name, _ := strconv.Unquote(fmt.Sprint(port["name"]))
+				ref, err := values.Add(cueformat.MustParse(portSchema), number, objName, containerName, "ports", portKeys.Key(portKey(name, number)))
+				if err != nil {
+					return nil, fmt.Errorf("%w: unable to set container port", err)
+				}
+				port["containerPort"] = ref
+				res = append(res, timonify.ContainerPort{Name: name, Port: int32(number), Reference: ref})
+			}
+			if len(ports) != 0 {
+				if err = unstructured.SetNestedSlice(container, ports, "ports"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(containers) != 0 {
+			if err = unstructured.SetNestedSlice(specMap, containers, containerKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// containerPortKeys returns #config keys of the container ports.
+func containerPortKeys(ports []interface{}) timonify.NameKeys {
+	names := make([]string, 0, len(ports))
+	for _, p := range ports {
+		port := p.(map[string]interface{})
+		if number, ok := port["containerPort"].(int64); ok {
+			names = append(names, portKey(unquote(fmt.Sprint(port["name"])), number))
+		}
+	}
+	return timonify.NewNameKeys(names...)
+}
+
+func portKey(name string, number int64) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("port%d", number)
+}
+
+func unquoteMap(m map[string]string) map[string]string {
+	res := make(map[string]string, len(m))
+	for k, v := range m {
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			v = unquoted
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// processProbes moves container probes to #config.<objName>.<containerName>.probes, each probe can be disabled.
+func processProbes(objName, containerName string, container map[string]interface{}, values *timonify.Values) error {
+	for _, p := range probes {
+		probe, exists, err := unstructured.NestedMap(container, p.field)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		probe["enabled"] = true
+		portSchemas, err := linkProbePorts(objName, containerName, probe, values)
+		if err != nil {
+			return err
+		}
+		schema := cueformat.MustParse(fmt.Sprintf(probeSchema, portSchemas))
+		_, err = values.Add(schema, probe, objName, containerName, "probes", p.name)
+		if err != nil {
+			return fmt.Errorf("%w: unable to set container %s", err, p.field)
+		}
+		delete(container, p.field)
+		container[cueformat.If(fmt.Sprintf(probeEnabledTemplate, objName, containerName, p.name))] = map[string]interface{}{
+			p.field: fmt.Sprintf(probeTemplate, objName, containerName, p.name),
+		}
+	}
+	return nil
+}
+
+// linkProbePorts replaces numeric probe ports matching container ports with the port config defaults.
+// Returns schema of linked ports.
+func linkProbePorts(objName, containerName string, probe map[string]interface{}, values *timonify.Values) (string, error) {
+	ports, _, err := unstructured.NestedMap(values.Values, objName, timonify.LabelName(containerName), "ports")
+	if err != nil {
+		return "", err
+	}
+	var schema strings.Builder
+	for _, handler := range probeHandlers {
+		number, ok, _ := unstructured.NestedInt64(probe, handler, "port")
+		if !ok {
+			continue
+		}
+		for key, port := range ports {
+			if port != number {
+				continue
+			}
+			unstructured.RemoveNestedField(probe, handler, "port")
+			schema.WriteString(fmt.Sprintf(probePortSchema, handler, timonify.LabelSource(key)))
+			break
+		}
+	}
+	return schema.String(), nil
 }
 
 func processPodSpec(name string, appMeta timonify.AppMetadata, pod *corev1.PodSpec) (*timonify.Values, error) {
 	values := timonify.NewValues()
+	names := make([]string, 0, len(pod.Containers)+len(pod.InitContainers))
+	for _, c := range append(append([]corev1.Container{}, pod.Containers...), pod.InitContainers...) {
+		names = append(names, unquote(c.Name))
+	}
+	keys := timonify.NewNameKeys(names...)
 	for i, c := range pod.Containers {
-		processed, err := processPodContainer(name, appMeta, c, values)
+		processed, err := processPodContainer(name, keys.Key(unquote(c.Name)), appMeta, c, values)
 		if err != nil {
 			return nil, err
 		}
@@ -149,7 +492,7 @@ func processPodSpec(name string, appMeta timonify.AppMetadata, pod *corev1.PodSp
 	}
 
 	for i, c := range pod.InitContainers {
-		processed, err := processPodContainer(name, appMeta, c, values)
+		processed, err := processPodContainer(name, keys.Key(unquote(c.Name)), appMeta, c, values)
 		if err != nil {
 			return nil, err
 		}
@@ -158,22 +501,22 @@ func processPodSpec(name string, appMeta timonify.AppMetadata, pod *corev1.PodSp
 
 	for _, v := range pod.Volumes {
 		if v.ConfigMap != nil {
-			v.ConfigMap.Name = appMeta.TemplatedName(v.ConfigMap.Name)
+			v.ConfigMap.Name = processor.TemplatedQuotedName(appMeta, v.ConfigMap.Name).(string)
 		}
 		if v.Secret != nil {
-			v.Secret.SecretName = appMeta.TemplatedName(v.Secret.SecretName)
+			v.Secret.SecretName = processor.TemplatedQuotedName(appMeta, v.Secret.SecretName).(string)
 		}
 	}
 	pod.ServiceAccountName = appMeta.TemplatedName(pod.ServiceAccountName)
 
 	for i, s := range pod.ImagePullSecrets {
-		pod.ImagePullSecrets[i].Name = appMeta.TemplatedName(s.Name)
+		pod.ImagePullSecrets[i].Name = processor.TemplatedQuotedName(appMeta, s.Name).(string)
 	}
 
 	return values, nil
 }
 
-func processPodContainer(name string, appMeta timonify.AppMetadata, c corev1.Container, values *timonify.Values) (corev1.Container, error) {
+func processPodContainer(name, containerName string, appMeta timonify.AppMetadata, c corev1.Container, values *timonify.Values) (corev1.Container, error) {
 	image, err := strconv.Unquote(c.Image)
 	if err != nil {
 		return c, fmt.Errorf("%w: unable to unquote image", err)
@@ -181,8 +524,8 @@ func processPodContainer(name string, appMeta timonify.AppMetadata, c corev1.Con
 	index := strings.LastIndex(image, ":")
 	var isDigest bool
 	if strings.Contains(image, "@") && strings.Count(image, ":") >= 2 {
-		last := strings.LastIndex(c.Image, ":")
-		index = strings.LastIndex(c.Image[:last], ":")
+		last := strings.LastIndex(image, ":")
+		index = strings.LastIndex(image[:last], ":")
 		isDigest = true
 	}
 	if index < 0 {
@@ -195,7 +538,6 @@ func processPodContainer(name string, appMeta timonify.AppMetadata, c corev1.Con
 	} else {
 		tag = image[index+1:]
 	}
-	containerName := strcase.ToLowerCamel(c.Name)
 	c.Image = fmt.Sprintf("#config.%[1]s.%[2]s.image.reference", name, containerName)
 
 	if _, err := values.Add(nil, strconv.Quote(repo), name, containerName, "image", "repository"); err != nil {
@@ -214,23 +556,27 @@ func processPodContainer(name string, appMeta timonify.AppMetadata, c corev1.Con
 		return c, fmt.Errorf("%w: unable to set image value field", err)
 	}
 
-	c, err = processEnv(name, appMeta, c, values)
+	// checked before env values are templated
+	domainEnv := referencesClusterDomain(c)
+	c, err = processEnv(name, containerName, appMeta, c, values)
 	if err != nil {
 		return c, err
 	}
 
 	for _, e := range c.EnvFrom {
 		if e.SecretRef != nil {
-			e.SecretRef.Name = appMeta.TemplatedName(e.SecretRef.Name)
+			e.SecretRef.Name = processor.TemplatedQuotedName(appMeta, e.SecretRef.Name).(string)
 		}
 		if e.ConfigMapRef != nil {
-			e.ConfigMapRef.Name = appMeta.TemplatedName(e.ConfigMapRef.Name)
+			e.ConfigMapRef.Name = processor.TemplatedQuotedName(appMeta, e.ConfigMapRef.Name).(string)
 		}
 	}
-	c.Env = append(c.Env, corev1.EnvVar{
-		Name:  strconv.Quote(cluster.DomainEnv),
-		Value: fmt.Sprintf("#config.%s", cluster.DomainKey),
-	})
+	if domainEnv {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  strconv.Quote(cluster.DomainEnv),
+			Value: fmt.Sprintf("#config.%s", cluster.DomainKey),
+		})
+	}
 	for k, v := range c.Resources.Requests {
 		_, err = values.Add(ast.NewSel(ast.NewIdent("timoniv1"), "#ResourceList"), strconv.Quote(v.String()), name, containerName, "resources", "requests", k.String())
 		if err != nil {
@@ -263,26 +609,61 @@ func processPodContainer(name string, appMeta timonify.AppMetadata, c corev1.Con
 	return c, nil
 }
 
-func processEnv(name string, appMeta timonify.AppMetadata, c corev1.Container, values *timonify.Values) (corev1.Container, error) {
-	containerName := strcase.ToLowerCamel(c.Name)
+// referencesClusterDomain returns true if container env values or args reference the default cluster domain
+// and the container has no cluster domain env yet.
+func referencesClusterDomain(c corev1.Container) bool {
+	var found bool
+	for _, e := range c.Env {
+		if unquote(e.Name) == cluster.DomainEnv {
+			return false
+		}
+		found = found || e.ValueFrom == nil && cluster.References(unquote(e.Value))
+	}
+	for _, arg := range c.Args {
+		found = found || cluster.References(unquote(arg))
+	}
+	return found
+}
+
+func processEnv(name, containerName string, appMeta timonify.AppMetadata, c corev1.Container, values *timonify.Values) (corev1.Container, error) {
+	names := make([]string, 0, len(c.Env))
+	for _, e := range c.Env {
+		names = append(names, unquote(e.Name))
+	}
+	envKeys := timonify.NewNameKeys(names...)
+	for key, names := range envKeys.Clashes() {
+		logrus.Warnf("env vars %q of %q container %q normalize to the same value key %q, keeping their names quoted",
+			names, name, unquote(c.Name), key)
+	}
 	for i := 0; i < len(c.Env); i++ {
 		if c.Env[i].ValueFrom != nil {
 			switch {
 			case c.Env[i].ValueFrom.SecretKeyRef != nil:
-				c.Env[i].ValueFrom.SecretKeyRef.Name = appMeta.TemplatedName(c.Env[i].ValueFrom.SecretKeyRef.Name)
+				c.Env[i].ValueFrom.SecretKeyRef.Name = processor.TemplatedQuotedName(appMeta, c.Env[i].ValueFrom.SecretKeyRef.Name).(string)
 			case c.Env[i].ValueFrom.ConfigMapKeyRef != nil:
-				c.Env[i].ValueFrom.ConfigMapKeyRef.Name = appMeta.TemplatedName(c.Env[i].ValueFrom.ConfigMapKeyRef.Name)
+				c.Env[i].ValueFrom.ConfigMapKeyRef.Name = processor.TemplatedQuotedName(appMeta, c.Env[i].ValueFrom.ConfigMapKeyRef.Name).(string)
 			case c.Env[i].ValueFrom.FieldRef != nil, c.Env[i].ValueFrom.ResourceFieldRef != nil:
 				// nothing to change here, keep the original value
 			}
 			continue
 		}
 
-		_, err := values.Add(ast.NewIdent("string"), c.Env[i].Value, name, containerName, "env", strcase.ToLowerCamel(strings.ToLower(c.Env[i].Name)))
+		if unquote(c.Env[i].Name) == cluster.DomainEnv {
+			c.Env[i].Value = fmt.Sprintf("#config.%s", cluster.DomainKey)
+			continue
+		}
+		if templated, ok := cluster.Templated(c.Env[i].Value); ok {
+			// value depends on the cluster domain, so it is kept in the template
+			c.Env[i].Value = templated
+			continue
+		}
+
+		envName := envKeys.Key(unquote(c.Env[i].Name))
+		_, err := values.Add(ast.NewIdent("string"), c.Env[i].Value, name, containerName, "env", envName)
 		if err != nil {
 			return c, fmt.Errorf("%w: unable to set deployment value field", err)
 		}
-		c.Env[i].Value = fmt.Sprintf(envValue, name, containerName, "env", strcase.ToLowerCamel(strings.ToLower(c.Env[i].Name)))
+		c.Env[i].Value = fmt.Sprintf(envValue, name, containerName, "env", envName)
 	}
 	return c, nil
 }
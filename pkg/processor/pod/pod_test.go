@@ -1,11 +1,17 @@
 package pod
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
 	"github.com/syndicut/timonify/pkg/metadata"
-	"github.com/syndicut/timonify/pkg/timonify"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/stretchr/testify/assert"
@@ -88,6 +94,100 @@ spec:
         - containerPort: 80
 `
 
+	strDeploymentWithProbes = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.14.2
+        ports:
+        - name: http
+          containerPort: 80
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 80
+          periodSeconds: 20
+        startupProbe:
+          exec:
+            command: [cat, /tmp/healthy]
+`
+
+	strDeploymentWithNodeSelector = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      nodeSelector:
+        disktype: ssd
+      containers:
+      - name: nginx
+        image: nginx:1.14.2
+`
+
+	strDeploymentWithImagePullSecrets = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: registry
+      containers:
+      - name: nginx
+        image: nginx:1.14.2
+`
+
+	strDeploymentWithVolumes = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.14.2
+        volumeMounts:
+        - name: config
+          mountPath: /etc/nginx
+      volumes:
+      - name: config
+        configMap:
+          name: nginx-config
+      - name: cache
+        emptyDir:
+          sizeLimit: 1Gi
+      - name: logs
+        hostPath:
+          path: /var/log/nginx
+`
+
+	strConfigMap = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx-config
+`
+
+	strSecret = `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx-secret
+`
+
 	strDeploymentWithPort = `
 apiVersion: apps/v1
 kind: Deployment
@@ -118,161 +218,361 @@ func Test_pod_Process(t *testing.T) {
 		var deploy appsv1.Deployment
 		obj := internal.GenerateObj(strDeployment)
 		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
-		specMap, tmpl, err := ProcessSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		specMap, tmpl, err := processSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
 		assert.NoError(t, err)
 
 		assert.Equal(t, map[string]interface{}{
 			"containers": []interface{}{
 				map[string]interface{}{
-					"args": "{{- toYaml .Values.nginx.nginx.args | nindent 8 }}",
-					"env": []interface{}{
-						map[string]interface{}{
-							"name":  "KUBERNETES_CLUSTER_DOMAIN",
-							"value": "{{ quote .Values.kubernetesClusterDomain }}",
-						},
-					},
-					"image": "{{ .Values.nginx.nginx.image.repository }}:{{ .Values.nginx.nginx.image.tag | default .Module.AppVersion }}",
-					"name":  "nginx", "ports": []interface{}{
+					"args":  fmt.Sprintf(argsTemplate, "", "nginx", "nginx", "{}"),
+					"image": "#config.nginx.nginx.image.reference",
+					"name":  "\"nginx\"",
+					"ports": []interface{}{
 						map[string]interface{}{
-							"containerPort": int64(80),
+							"containerPort": "#config.nginx.nginx.ports.port80",
 						},
 					},
-					"resources": map[string]interface{}{},
+					"resources":    map[string]interface{}{},
+					"volumeMounts": "#config.nginx.nginx.extraVolumeMounts",
 				},
 			},
+			"volumes": "#config.nginx.extraVolumes",
 		}, specMap)
 
-		assert.Equal(t, timonify.Values{
+		assert.Equal(t, map[string]interface{}{
 			"nginx": map[string]interface{}{
+				"extraVolumes": []interface{}{},
 				"nginx": map[string]interface{}{
+					"extraVolumeMounts": []interface{}{},
 					"image": map[string]interface{}{
-						"repository": "nginx",
-						"tag":        "1.14.2",
+						"repository": "\"nginx\"",
+						"tag":        "\"1.14.2\"",
+						"digest":     "\"\"",
+					},
+					"ports": map[string]interface{}{
+						"port80": int64(80),
 					},
-					"args": []interface{}{
-						"--test",
-						"--arg",
+					"flags": map[string]interface{}{
+						"test": true,
+						"arg":  true,
 					},
+					"extraArgs": []interface{}{},
 				},
 			},
-		}, tmpl)
+		}, tmpl.Values)
 	})
 
 	t.Run("deployment with no args", func(t *testing.T) {
 		var deploy appsv1.Deployment
 		obj := internal.GenerateObj(strDeploymentWithNoArgs)
 		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
-		specMap, tmpl, err := ProcessSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		specMap, tmpl, err := processSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
 		assert.NoError(t, err)
 
 		assert.Equal(t, map[string]interface{}{
 			"containers": []interface{}{
 				map[string]interface{}{
-					"env": []interface{}{
-						map[string]interface{}{
-							"name":  "KUBERNETES_CLUSTER_DOMAIN",
-							"value": "{{ quote .Values.kubernetesClusterDomain }}",
-						},
-					},
-					"image": "{{ .Values.nginx.nginx.image.repository }}:{{ .Values.nginx.nginx.image.tag | default .Module.AppVersion }}",
-					"name":  "nginx", "ports": []interface{}{
+					"image": "#config.nginx.nginx.image.reference",
+					"name":  "\"nginx\"",
+					"ports": []interface{}{
 						map[string]interface{}{
-							"containerPort": int64(80),
+							"containerPort": "#config.nginx.nginx.ports.port80",
 						},
 					},
-					"resources": map[string]interface{}{},
+					"resources":    map[string]interface{}{},
+					"volumeMounts": "#config.nginx.nginx.extraVolumeMounts",
 				},
 			},
+			"volumes": "#config.nginx.extraVolumes",
 		}, specMap)
 
-		assert.Equal(t, timonify.Values{
+		assert.Equal(t, map[string]interface{}{
 			"nginx": map[string]interface{}{
+				"extraVolumes": []interface{}{},
 				"nginx": map[string]interface{}{
+					"extraVolumeMounts": []interface{}{},
 					"image": map[string]interface{}{
-						"repository": "nginx",
-						"tag":        "1.14.2",
+						"repository": "\"nginx\"",
+						"tag":        "\"1.14.2\"",
+						"digest":     "\"\"",
+					},
+					"ports": map[string]interface{}{
+						"port80": int64(80),
 					},
 				},
 			},
-		}, tmpl)
+		}, tmpl.Values)
 	})
 
 	t.Run("deployment with image tag and digest", func(t *testing.T) {
 		var deploy appsv1.Deployment
 		obj := internal.GenerateObj(strDeploymentWithTagAndDigest)
 		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
-		specMap, tmpl, err := ProcessSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		specMap, tmpl, err := processSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
 		assert.NoError(t, err)
 
 		assert.Equal(t, map[string]interface{}{
 			"containers": []interface{}{
 				map[string]interface{}{
-					"env": []interface{}{
+					"image": "#config.nginx.nginx.image.reference",
+					"name":  "\"nginx\"",
+					"ports": []interface{}{
 						map[string]interface{}{
-							"name":  "KUBERNETES_CLUSTER_DOMAIN",
-							"value": "{{ quote .Values.kubernetesClusterDomain }}",
+							"containerPort": "#config.nginx.nginx.ports.port80",
 						},
 					},
-					"image": "{{ .Values.nginx.nginx.image.repository }}:{{ .Values.nginx.nginx.image.tag | default .Module.AppVersion }}",
-					"name":  "nginx", "ports": []interface{}{
-						map[string]interface{}{
-							"containerPort": int64(80),
-						},
-					},
-					"resources": map[string]interface{}{},
+					"resources":    map[string]interface{}{},
+					"volumeMounts": "#config.nginx.nginx.extraVolumeMounts",
 				},
 			},
+			"volumes": "#config.nginx.extraVolumes",
 		}, specMap)
 
-		assert.Equal(t, timonify.Values{
+		assert.Equal(t, map[string]interface{}{
 			"nginx": map[string]interface{}{
+				"extraVolumes": []interface{}{},
 				"nginx": map[string]interface{}{
+					"extraVolumeMounts": []interface{}{},
 					"image": map[string]interface{}{
-						"repository": "nginx",
-						"tag":        "1.14.2@sha256:cb5c1bddd1b5665e1867a7fa1b5fa843a47ee433bbb75d4293888b71def53229",
+						"repository": "\"nginx\"",
+						"tag":        "\"\"",
+						"digest":     "\"1.14.2@sha256:cb5c1bddd1b5665e1867a7fa1b5fa843a47ee433bbb75d4293888b71def53229\"",
+					},
+					"ports": map[string]interface{}{
+						"port80": int64(80),
 					},
 				},
 			},
-		}, tmpl)
+		}, tmpl.Values)
 	})
 
 	t.Run("deployment with image tag and port", func(t *testing.T) {
 		var deploy appsv1.Deployment
 		obj := internal.GenerateObj(strDeploymentWithPort)
 		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
-		specMap, tmpl, err := ProcessSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		specMap, tmpl, err := processSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
 		assert.NoError(t, err)
 
 		assert.Equal(t, map[string]interface{}{
 			"containers": []interface{}{
 				map[string]interface{}{
-					"env": []interface{}{
+					"image": "#config.nginx.nginx.image.reference",
+					"name":  "\"nginx\"",
+					"ports": []interface{}{
 						map[string]interface{}{
-							"name":  "KUBERNETES_CLUSTER_DOMAIN",
-							"value": "{{ quote .Values.kubernetesClusterDomain }}",
+							"containerPort": "#config.nginx.nginx.ports.port80",
 						},
 					},
-					"image": "{{ .Values.nginx.nginx.image.repository }}:{{ .Values.nginx.nginx.image.tag | default .Module.AppVersion }}",
-					"name":  "nginx", "ports": []interface{}{
-						map[string]interface{}{
-							"containerPort": int64(80),
-						},
-					},
-					"resources": map[string]interface{}{},
+					"resources":    map[string]interface{}{},
+					"volumeMounts": "#config.nginx.nginx.extraVolumeMounts",
 				},
 			},
+			"volumes": "#config.nginx.extraVolumes",
 		}, specMap)
 
-		assert.Equal(t, timonify.Values{
+		assert.Equal(t, map[string]interface{}{
 			"nginx": map[string]interface{}{
+				"extraVolumes": []interface{}{},
 				"nginx": map[string]interface{}{
+					"extraVolumeMounts": []interface{}{},
 					"image": map[string]interface{}{
-						"repository": "localhost:6001/my_project",
-						"tag":        "latest",
+						"repository": "\"localhost:6001/my_project\"",
+						"tag":        "\"latest\"",
+						"digest":     "\"\"",
+					},
+					"ports": map[string]interface{}{
+						"port80": int64(80),
 					},
 				},
 			},
-		}, tmpl)
+		}, tmpl.Values)
+	})
+
+	t.Run("deployment with probes", func(t *testing.T) {
+		var deploy appsv1.Deployment
+		obj := internal.GenerateObj(strDeploymentWithProbes)
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		specMap, tmpl, err := processSpec("nginx", &metadata.Service{}, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
+		assert.NoError(t, err)
+
+		container := specMap["containers"].([]interface{})[0].(map[string]interface{})
+		assert.NotContains(t, container, "livenessProbe")
+		assert.Equal(t, map[string]interface{}{
+			"livenessProbe": `{for k, v in #config.nginx.nginx.probes.liveness if k != "enabled" {(k): v}}`,
+		}, container[cue.If("#config.nginx.nginx.probes.liveness.enabled")])
+		assert.Contains(t, container, cue.If("#config.nginx.nginx.probes.startup.enabled"))
+		assert.NotContains(t, container, cue.If("#config.nginx.nginx.probes.readiness.enabled"))
+
+		probes := tmpl.Values["nginx"].(map[string]interface{})["nginx"].(map[string]interface{})["probes"].(map[string]interface{})
+		// probe port is linked to the container port in #Config
+		assert.Equal(t, map[string]interface{}{
+			"enabled": true,
+			"httpGet": map[string]interface{}{
+				"path": "\"/healthz\"",
+			},
+			"periodSeconds": int64(20),
+		}, probes["liveness"])
+		cfg, err := cueformat.Node(tmpl.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "httpGet: port: *ports.http | int")
+		assert.Equal(t, "#config.nginx.nginx.ports.http", container["ports"].([]interface{})[0].(map[string]interface{})["containerPort"])
+		assert.Equal(t, true, probes["startup"].(map[string]interface{})["enabled"])
+	})
+
+	t.Run("deployment with generated defaults", func(t *testing.T) {
+		var deploy appsv1.Deployment
+		obj := internal.GenerateObj(strDeploymentWithNodeSelector)
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		appMeta := metadata.New(config.Config{GenerateDefaults: true})
+		specMap, tmpl, err := ProcessSpec("nginx", appMeta, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels, nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "#config.nginx.nodeSelector", specMap["nodeSelector"])
+		assert.Equal(t, map[string]interface{}{"disktype": "\"ssd\""}, tmpl.Values["nginx"].(map[string]interface{})["nodeSelector"])
+		assert.NotContains(t, specMap, cue.If("#config.nginx.nodeSelector != _|_"))
+		assert.Equal(t, map[string]interface{}{
+			"tolerations": "#config.nginx.tolerations",
+		}, specMap[cue.If("#config.nginx.tolerations != _|_")])
+		assert.Contains(t, specMap, cue.If("#config.nginx.priorityClassName != _|_"))
+
+		cfg, err := cueformat.Node(tmpl.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "tolerations?: [...corev1.#Toleration]")
+		assert.Contains(t, string(cfg), "affinity?: corev1.#Affinity")
+		assert.Contains(t, string(cfg), "topologySpreadConstraints?: [...corev1.#TopologySpreadConstraint]")
+		assert.Contains(t, string(cfg), "priorityClassName?: string")
 	})
 
+	t.Run("deployment with image pull secrets", func(t *testing.T) {
+		var deploy appsv1.Deployment
+		obj := internal.GenerateObj(strDeploymentWithImagePullSecrets)
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		appMeta := metadata.New(config.Config{ImagePullSecrets: true})
+		specMap, tmpl, err := ProcessSpec("nginx", appMeta, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels, nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, `[{
+	name: "registry"
+}, for v in #config.imagePullSecrets {v}]`, specMap["imagePullSecrets"])
+		assert.Equal(t, []interface{}{}, tmpl.Values["imagePullSecrets"])
+		cfg, err := cueformat.Node(tmpl.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "imagePullSecrets: [...corev1.#LocalObjectReference]")
+
+		specMap, _, err = ProcessSpec("app", appMeta, corev1.PodSpec{Containers: []corev1.Container{{Name: `"app"`, Image: `"app:1"`}}}, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "#config.imagePullSecrets", specMap["imagePullSecrets"])
+	})
+
+	t.Run("deployment with volumes", func(t *testing.T) {
+		var deploy appsv1.Deployment
+		obj := internal.GenerateObj(strDeploymentWithVolumes)
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+		assert.NoError(t, err)
+		format.QuoteStringsInStruct(&deploy)
+		appMeta := metadata.New(config.Config{})
+		appMeta.Load(obj)
+		appMeta.Load(internal.GenerateObj(strConfigMap))
+		specMap, tmpl, err := processSpec("nginx", appMeta, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels)
+		assert.NoError(t, err)
+
+		volumes := strings.Join(strings.Fields(specMap["volumes"].(string)), " ")
+		assert.Contains(t, volumes, `configMap: { name: #config.metadata.name + "-config" }`)
+		assert.Contains(t, volumes, "sizeLimit: #config.nginx.volumes.cache.sizeLimit")
+		assert.Contains(t, volumes, "path: #config.nginx.volumes.logs.hostPath")
+		assert.Contains(t, volumes, "for v in #config.nginx.extraVolumes {v}]")
+
+		container := specMap["containers"].([]interface{})[0].(map[string]interface{})
+		assert.Contains(t, container["volumeMounts"], "for v in #config.nginx.nginx.extraVolumeMounts {v}]")
+		// container has no ports, so none are added
+		assert.NotContains(t, container, "ports")
+
+		assert.Equal(t, map[string]interface{}{
+			"cache": map[string]interface{}{"sizeLimit": `"1Gi"`},
+			"logs":  map[string]interface{}{"hostPath": `"/var/log/nginx"`},
+		}, tmpl.Values["nginx"].(map[string]interface{})["volumes"])
+		cfg, err := cueformat.Node(tmpl.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "extraVolumes: [...corev1.#Volume]")
+		assert.Contains(t, string(cfg), "extraVolumeMounts: [...corev1.#VolumeMount]")
+	})
+
+	t.Run("clashing container and env names", func(t *testing.T) {
+		spec := corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  `"app-1"`,
+				Image: `"app:1"`,
+				Env: []corev1.EnvVar{
+					{Name: `"MY_VAR"`, Value: `"a"`},
+					{Name: `"my-var"`, Value: `"b"`},
+					{Name: `"OTHER_VAR"`, Value: `"c"`},
+				},
+			}},
+			InitContainers: []corev1.Container{{Name: `"app1"`, Image: `"init:1"`}},
+		}
+		specMap, tmpl, err := processSpec("app", metadata.New(config.Config{}), spec, nil)
+		assert.NoError(t, err)
+
+		container := specMap["containers"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, `#config.app."app-1".image.reference`, container["image"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": `"MY_VAR"`, "value": `#config.app."app-1".env."MY_VAR"`},
+			map[string]interface{}{"name": `"my-var"`, "value": `#config.app."app-1".env."my-var"`},
+			map[string]interface{}{"name": `"OTHER_VAR"`, "value": `#config.app."app-1".env.otherVar`},
+		}, container["env"])
+		initContainer := specMap["initContainers"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, `#config.app."app1".image.reference`, initContainer["image"])
+
+		values := tmpl.Values["app"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"MY_VAR": `"a"`, "my-var": `"b"`, "otherVar": `"c"`},
+			values["app-1"].(map[string]interface{})["env"])
+		assert.Contains(t, values, "app1")
+	})
+
+	t.Run("env references to module objects", func(t *testing.T) {
+		appMeta := metadata.New(config.Config{})
+		appMeta.Load(internal.GenerateObj(strConfigMap))
+		appMeta.Load(internal.GenerateObj(strSecret))
+		spec := corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  `"nginx"`,
+				Image: `"nginx:1"`,
+				Env: []corev1.EnvVar{
+					{Name: `"PASSWORD"`, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: `"nginx-secret"`}, Key: `"password"`}}},
+					{Name: `"MODE"`, ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: `"nginx-config"`}, Key: `"mode"`}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: `"nginx-secret"`}}},
+					{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: `"other-config"`}}},
+				},
+			}},
+		}
+		specMap, _, err := processSpec("nginx", appMeta, spec, nil)
+		assert.NoError(t, err)
+
+		container := specMap["containers"].([]interface{})[0].(map[string]interface{})
+		env := container["env"].([]interface{})
+		assert.Equal(t, `#config.metadata.name + "-secret"`, env[0].(map[string]interface{})["valueFrom"].(map[string]interface{})["secretKeyRef"].(map[string]interface{})["name"])
+		assert.Equal(t, `#config.metadata.name + "-config"`, env[1].(map[string]interface{})["valueFrom"].(map[string]interface{})["configMapKeyRef"].(map[string]interface{})["name"])
+		envFrom := container["envFrom"].([]interface{})
+		assert.Equal(t, `#config.metadata.name + "-secret"`, envFrom[0].(map[string]interface{})["secretRef"].(map[string]interface{})["name"])
+		// objects which are not a part of the module are kept as is
+		assert.Equal(t, `"other-config"`, envFrom[1].(map[string]interface{})["configMapRef"].(map[string]interface{})["name"])
+	})
 }
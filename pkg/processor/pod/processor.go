@@ -0,0 +1,163 @@
+package pod
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podGVC = schema.GroupVersionKind{
+	Group:   "",
+	Version: "v1",
+	Kind:    "Pod",
+}
+
+// helmHookAnnotation marks Helm hooks. Helm test hooks become Timoni module tests.
+const helmHookAnnotation = "helm.sh/hook"
+
+var podTempl, _ = template.New("pod").Parse(
+	`package templates
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+{{ .Type }}: corev1.#Pod & {
+	#config:    #Config
+{{ .Meta }}
+	spec: corev1.#PodSpec & {{ .Spec }}
+}`)
+
+// New creates processor for k8s Pod resource.
+func New() timonify.Processor {
+	return &pod{}
+}
+
+type pod struct{}
+
+// Process k8s Pod object into template. Returns false if not capable of processing given resource type.
+func (p pod) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind() != podGVC {
+		return false, nil, nil
+	}
+	po := corev1.Pod{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &po)
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to cast to pod", err)
+	}
+	format.QuoteStringsInStruct(&po)
+
+	test := isHelmTest(obj)
+	if test {
+		// test is applied by Timoni when enabled, helm hook annotations are meaningless here
+		// the input object is read by later steps, so annotations are removed from a copy
+		obj = obj.DeepCopy()
+		annotations := obj.GetAnnotations()
+		for k := range annotations {
+			if strings.HasPrefix(k, helmHookAnnotation) {
+				delete(annotations, k)
+			}
+		}
+		obj.SetAnnotations(annotations)
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+
+	name := appMeta.TrimName(obj.GetName())
+	rawPodSpec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	specMap, values, err := ProcessSpec(appMeta.ValuesName(obj), appMeta, po.Spec, po.ObjectMeta.Labels, rawPodSpec)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, err := cue.Marshal(specMap, 2, true)
+	if err != nil {
+		return true, nil, err
+	}
+	spec = strings.ReplaceAll(spec, "'", "")
+
+	res := &podResult{
+		name:   name,
+		test:   test,
+		values: values,
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	res.data.Spec = spec
+	return true, res, nil
+}
+
+func isHelmTest(obj *unstructured.Unstructured) bool {
+	for _, hook := range strings.Split(obj.GetAnnotations()[helmHookAnnotation], ",") {
+		switch strings.TrimSpace(hook) {
+		case "test", "test-success":
+			return true
+		}
+	}
+	return false
+}
+
+type podResult struct {
+	name string
+	test bool
+	data struct {
+		Type string
+		Meta string
+		Spec string
+	}
+	values *timonify.Values
+}
+
+var _ timonify.TestTemplate = &podResult{}
+
+func (r *podResult) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + "Pod"
+}
+
+func (r *podResult) Filename() string {
+	return r.name + "-pod.cue"
+}
+
+func (r *podResult) Values() *timonify.Values {
+	return r.values
+}
+
+func (r *podResult) Write(writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := podTempl.Execute(&buf, r.data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+func (r *podResult) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *podResult) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + "Pod")
+}
+
+func (r *podResult) IsTest() bool {
+	return r.test
+}
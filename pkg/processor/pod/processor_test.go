@@ -0,0 +1,81 @@
+package pod
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/timonify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+)
+
+const (
+	strPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+  - name: shell
+    image: busybox:1.36
+    command: ["sleep", "infinity"]
+`
+	strTestPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: test-connection
+  annotations:
+    helm.sh/hook: test
+    helm.sh/hook-delete-policy: hook-succeeded
+    team: web
+spec:
+  restartPolicy: Never
+  containers:
+  - name: wget
+    image: busybox:1.36
+    command: ['wget']
+    args: ['my-app:80']
+`
+)
+
+func Test_pod_Processor(t *testing.T) {
+	var testInstance pod
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(strPod)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+		assert.False(t, tpl.(timonify.TestTemplate).IsTest())
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "#DebugPod: corev1.#Pod & {")
+		assert.Contains(t, out, "image: #config.debug.shell.image.reference")
+		assert.Equal(t, "debug-pod.cue", tpl.Filename())
+	})
+	t.Run("helm test hook", func(t *testing.T) {
+		obj := internal.GenerateObj(strTestPod)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+		assert.True(t, tpl.(timonify.TestTemplate).IsTest())
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		assert.NotContains(t, buf.String(), "helm.sh/hook")
+		assert.Contains(t, buf.String(), "team")
+		// input object is left intact for later steps
+		assert.Contains(t, obj.GetAnnotations(), "helm.sh/hook")
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
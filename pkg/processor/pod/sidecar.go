@@ -0,0 +1,79 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	sidecarEnabledSchema  = "*true | bool"
+	sidecarTemplate       = "if #config.%[1]s.%[2]s.enabled {%[3]s}"
+	extraContainersSchema = "[...corev1.#Container]"
+	restartPolicyAlways   = "Always"
+)
+
+// sidecarNames returns names of init containers with restartPolicy: Always, which are native sidecars.
+func sidecarNames(rawSpec map[string]interface{}) map[string]bool {
+	res := map[string]bool{}
+	initContainers, _, _ := unstructured.NestedSlice(rawSpec, "initContainers")
+	for _, c := range initContainers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if policy, _, _ := unstructured.NestedString(container, "restartPolicy"); policy == restartPolicyAlways {
+			name, _, _ := unstructured.NestedString(container, "name")
+			res[name] = true
+		}
+	}
+	return res
+}
+
+// processSidecars keeps restartPolicy of native sidecars and makes each of them optional with
+// #config.<objName>.<containerName>.enabled toggle.
+func processSidecars(objName string, sidecars map[string]bool, specMap map[string]interface{}, values *timonify.Values) error {
+	if len(sidecars) == 0 {
+		return nil
+	}
+	initContainers, _, err := unstructured.NestedSlice(specMap, "initContainers")
+	if err != nil {
+		return err
+	}
+	keys := timonify.ContainerKeys(specMap)
+	elems := make([]string, 0, len(initContainers))
+	for _, c := range initContainers {
+		container := c.(map[string]interface{})
+		name := unquote(fmt.Sprint(container["name"]))
+		if sidecars[name] {
+			container["restartPolicy"] = `"` + restartPolicyAlways + `"`
+		}
+		elem, err := cueformat.Marshal(container, 0, true)
+		if err != nil {
+			return err
+		}
+		if sidecars[name] {
+			containerName := keys.Key(name)
+			_, err = values.Add(cueformat.MustParse(sidecarEnabledSchema), true, objName, containerName, "enabled")
+			if err != nil {
+				return fmt.Errorf("%w: unable to set sidecar toggle", err)
+			}
+			elem = fmt.Sprintf(sidecarTemplate, objName, containerName, elem)
+		}
+		elems = append(elems, elem)
+	}
+	specMap["initContainers"] = "[" + strings.Join(elems, ", ") + "]"
+	return nil
+}
+
+// processExtraContainers appends user defined #config.<objName>.extraContainers to the pod containers.
+func processExtraContainers(objName string, specMap map[string]interface{}, values *timonify.Values) error {
+	ref, err := values.Add(cueformat.MustParse(extraContainersSchema), []interface{}{}, objName, "extraContainers")
+	if err != nil {
+		return fmt.Errorf("%w: unable to set extra containers", err)
+	}
+	return appendList(specMap, "containers", ref)
+}
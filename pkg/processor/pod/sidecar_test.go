@@ -0,0 +1,88 @@
+package pod
+
+import (
+	"testing"
+
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const strDeploymentWithSidecar = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      initContainers:
+      - name: migrate
+        image: migrate:1
+      - name: log-shipper
+        image: fluent-bit:2
+        restartPolicy: Always
+      containers:
+      - name: app
+        image: app:1
+      ephemeralContainers:
+      - name: debug
+        image: busybox
+`
+
+func TestProcessSpec_sidecars(t *testing.T) {
+	var deploy appsv1.Deployment
+	obj := internal.GenerateObj(strDeploymentWithSidecar)
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+	assert.NoError(t, err)
+	format.QuoteStringsInStruct(&deploy)
+	rawSpec, _, _ := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+
+	appMeta := metadata.New(config.Config{})
+	specMap, tmpl, err := ProcessSpec("app", appMeta, deploy.Spec.Template.Spec, deploy.Spec.Template.Labels, rawSpec)
+	assert.NoError(t, err)
+
+	// ephemeral containers are kept as is
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": `"debug"`, "image": `"busybox"`, "resources": map[string]interface{}{}},
+	}, specMap["ephemeralContainers"])
+
+	initContainers := specMap["initContainers"].(string)
+	assert.Contains(t, initContainers, `image: #config.app.migrate.image.reference`)
+	assert.Contains(t, initContainers, `}, if #config.app.logShipper.enabled {{`)
+	assert.Contains(t, initContainers, `restartPolicy: "Always"`)
+	assert.Contains(t, initContainers, `image: #config.app.logShipper.image.reference`)
+	assert.Contains(t, specMap["containers"], "}, for v in #config.app.extraContainers {v}]")
+
+	values := tmpl.Values["app"].(map[string]interface{})
+	assert.Equal(t, true, values["logShipper"].(map[string]interface{})["enabled"])
+	assert.NotContains(t, values["migrate"], "enabled")
+	assert.Equal(t, []interface{}{}, values["extraContainers"])
+
+	cfg, err := cueformat.Node(tmpl.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cfg), "enabled: *true | bool")
+	assert.Contains(t, string(cfg), "extraContainers: [...corev1.#Container]")
+}
+
+func TestProcessSpec_noRawSpec(t *testing.T) {
+	var deploy appsv1.Deployment
+	obj := internal.GenerateObj(strDeploymentWithSidecar)
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deploy)
+	assert.NoError(t, err)
+	format.QuoteStringsInStruct(&deploy)
+
+	// without raw spec restartPolicy of init containers is unknown, they are kept as is.
+	specMap, _, err := ProcessSpec("app", metadata.New(config.Config{}), deploy.Spec.Template.Spec, deploy.Spec.Template.Labels, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, []interface{}{}, specMap["initContainers"])
+}
@@ -0,0 +1,219 @@
+package replicaset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/processor/pod"
+	"github.com/syndicut/timonify/pkg/timonify"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var replicaSetGVC = schema.GroupVersionKind{
+	Group:   "apps",
+	Version: "v1",
+	Kind:    "ReplicaSet",
+}
+
+var replicaSetTempl, _ = template.New("replicaSet").Parse(
+	`package templates
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+{{ .Type }}: appsv1.#ReplicaSet & {
+	#config:    #Config
+{{ .Meta }}
+	spec: appsv1.#ReplicaSetSpec & {
+{{- if .Replicas }}
+{{ .Replicas }}
+{{- end }}
+{{- if .MinReadySeconds }}
+{{ .MinReadySeconds }}
+{{- end }}
+{{ .Selector }}
+		template: {
+			metadata: {
+				labels: {{ .PodLabels }}
+{{- .PodAnnotations }}
+			}
+			spec: corev1.#PodSpec & {{ .Spec }}
+		}
+	}
+}`)
+
+const selectorTempl = `selector: matchLabels: %[1]s
+%[2]s`
+
+// New creates processor for k8s ReplicaSet resource.
+func New() timonify.Processor {
+	return &replicaSet{}
+}
+
+type replicaSet struct{}
+
+// Process k8s ReplicaSet object into template. Returns false if not capable of processing given resource type.
+func (r replicaSet) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind() != replicaSetGVC {
+		return false, nil, nil
+	}
+	rs := appsv1.ReplicaSet{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &rs)
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to cast to replicaset", err)
+	}
+	format.QuoteStringsInStruct(&rs)
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+
+	values := timonify.NewValues()
+
+	name := appMeta.TrimName(obj.GetName())
+	nameCamel := appMeta.ValuesName(obj)
+
+	var replicas string
+	if rs.Spec.Replicas != nil {
+		replicasTpl, err := values.Add(cue.MustParse("int & >=0"), int64(*rs.Spec.Replicas), nameCamel, "replicas")
+		if err != nil {
+			return true, nil, err
+		}
+		replicas = fmt.Sprintf("replicas: %s", replicasTpl)
+	}
+
+	var minReadySeconds string
+	if rs.Spec.MinReadySeconds != 0 {
+		minReadySecondsTpl, err := values.Add(cue.MustParse("int & >=0"), int64(rs.Spec.MinReadySeconds), nameCamel, "minReadySeconds")
+		if err != nil {
+			return true, nil, err
+		}
+		minReadySeconds = fmt.Sprintf("minReadySeconds: %s", minReadySecondsTpl)
+	}
+
+	matchLabels, err := processor.SelectorLabels(rs.Spec.Selector.MatchLabels)
+	if err != nil {
+		return true, nil, err
+	}
+	matchExpr := ""
+	if rs.Spec.Selector.MatchExpressions != nil {
+		matchExpr, err = cue.Marshal(map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchExpressions": rs.Spec.Selector.MatchExpressions,
+			},
+		}, 4, true)
+		if err != nil {
+			return true, nil, err
+		}
+	}
+	selector := fmt.Sprintf(selectorTempl, matchLabels, matchExpr)
+	selector = strings.Trim(selector, " \n")
+	selector = string(cue.Indent([]byte(selector), 4))
+
+	podLabels, err := processor.SelectorLabels(rs.Spec.Template.ObjectMeta.Labels)
+	if err != nil {
+		return true, nil, err
+	}
+
+	podAnnotations := ""
+	if len(rs.Spec.Template.ObjectMeta.Annotations) != 0 {
+		podAnnotations, err = cue.Marshal(map[string]interface{}{"annotations": rs.Spec.Template.ObjectMeta.Annotations}, 6, true)
+		if err != nil {
+			return true, nil, err
+		}
+
+		podAnnotations = "\n" + podAnnotations
+	}
+
+	rawPodSpec, _, _ := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+	specMap, podValues, err := pod.ProcessSpec(nameCamel, appMeta, rs.Spec.Template.Spec, rs.Spec.Template.ObjectMeta.Labels, rawPodSpec)
+	if err != nil {
+		return true, nil, err
+	}
+	err = values.Merge(podValues)
+	if err != nil {
+		return true, nil, err
+	}
+
+	spec, err := cue.Marshal(specMap, 6, true)
+	if err != nil {
+		return true, nil, err
+	}
+	spec = strings.ReplaceAll(spec, "'", "")
+
+	res := &result{
+		name:   name,
+		values: values,
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	res.data.Replicas = replicas
+	res.data.MinReadySeconds = minReadySeconds
+	res.data.Selector = selector
+	res.data.PodLabels = podLabels
+	res.data.PodAnnotations = podAnnotations
+	res.data.Spec = spec
+	return true, res, nil
+}
+
+type result struct {
+	name string
+	data struct {
+		Type            string
+		Meta            string
+		Replicas        string
+		MinReadySeconds string
+		Selector        string
+		PodLabels       string
+		PodAnnotations  string
+		Spec            string
+	}
+	values *timonify.Values
+}
+
+func (r *result) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + "ReplicaSet"
+}
+
+func (r *result) Filename() string {
+	return r.name + "-replicaset.cue"
+}
+
+func (r *result) Values() *timonify.Values {
+	return r.values
+}
+
+func (r *result) Write(writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := replicaSetTempl.Execute(&buf, r.data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+func (r *result) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *result) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + "ReplicaSet")
+}
@@ -0,0 +1,64 @@
+package replicaset
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/syndicut/timonify/pkg/metadata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+)
+
+const strReplicaSet = `apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: frontend
+  labels:
+    app: guestbook
+spec:
+  replicas: 3
+  minReadySeconds: 10
+  selector:
+    matchLabels:
+      tier: frontend
+  template:
+    metadata:
+      labels:
+        tier: frontend
+    spec:
+      containers:
+      - name: php-redis
+        image: gcr.io/google_samples/gb-frontend:v3
+`
+
+func Test_replicaSet_Process(t *testing.T) {
+	var testInstance replicaSet
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(strReplicaSet)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "#FrontendReplicaSet: appsv1.#ReplicaSet & {")
+		assert.Contains(t, out, "replicas: #config.frontend.replicas")
+		assert.Contains(t, out, "minReadySeconds: #config.frontend.minReadySeconds")
+		assert.Contains(t, out, "image: #config.frontend.phpRedis.image.reference")
+		assert.Equal(t, "frontend-replicaset.cue", tpl.Filename())
+
+		values := tpl.Values().Values["frontend"].(map[string]interface{})
+		assert.Equal(t, int64(3), values["replicas"])
+		assert.Equal(t, int64(10), values["minReadySeconds"])
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
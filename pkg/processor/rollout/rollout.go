@@ -0,0 +1,435 @@
+package rollout
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	cueformat "cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/processor/pod"
+	"github.com/syndicut/timonify/pkg/timonify"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var rolloutGVC = schema.GroupVersionKind{
+	Group:   "argoproj.io",
+	Version: "v1alpha1",
+	Kind:    "Rollout",
+}
+
+// Argo Rollouts schemas are not vendored into timoni modules, so only the pod template is typed.
+var rolloutTempl, _ = template.New("rollout").Parse(
+	`package templates
+
+import (
+	corev1 "k8s.io/api/core/v1"
+{{- if .Checksums }}
+{{ .ChecksumImports }}
+{{- end }}
+)
+
+{{ .Type }}: {
+	#config:    #Config
+{{ .Meta }}
+	spec: {
+{{- if .Replicas }}
+{{ .Replicas }}
+{{- end }}
+{{- if .RevisionHistoryLimit }}
+{{ .RevisionHistoryLimit }}
+{{- end }}
+{{- if .Selector }}
+{{ .Selector }}
+{{- end }}
+{{- if .Strategy }}
+{{ .Strategy }}
+{{- end }}
+{{- if .Rest }}
+{{ .Rest }}
+{{- end }}
+{{- if .Spec }}
+		template: {
+			metadata: {
+				labels: {{ .PodLabels }}
+{{- .PodAnnotations }}
+{{- .Checksums }}
+			}
+			spec: corev1.#PodSpec & {{ .Spec }}
+		}
+{{- end }}
+	}
+}`)
+
+const (
+	// stepsSchema - canary steps are too diverse to type without Argo schemas.
+	stepsSchema = `[...{...}]`
+	// analysisSchema - background, pre- and post-promotion analysis.
+	analysisSchema = `{
+	templates?: [...{
+		templateName: string
+		clusterScope?: bool
+		...
+	}]
+	...
+}`
+)
+
+// New creates processor for Argo Rollouts Rollout resource.
+func New() timonify.Processor {
+	return &rollout{}
+}
+
+type rollout struct{}
+
+// Process Argo Rollout object into template. Returns false if not capable of processing given resource type.
+func (r rollout) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind() != rolloutGVC {
+		return false, nil, nil
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get rollout spec", err)
+	}
+
+	values := timonify.NewValues()
+
+	name := appMeta.TrimName(obj.GetName())
+	nameCamel := appMeta.ValuesName(obj)
+
+	replicas, err := processReplicas(nameCamel, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+
+	revisionHistoryLimit, err := processRevisionHistoryLimit(nameCamel, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+
+	strategy, err := processStrategy(nameCamel, appMeta, spec, values)
+	if err != nil {
+		return true, nil, err
+	}
+
+	res := &result{
+		name:    name,
+		values:  values,
+		appMeta: appMeta,
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	res.data.Replicas = replicas
+	res.data.RevisionHistoryLimit = revisionHistoryLimit
+	res.data.Strategy = strategy
+
+	if selector, ok := spec["selector"].(map[string]interface{}); ok {
+		format.QuoteStringsInStruct(&selector)
+		if matchLabels, ok, _ := unstructured.NestedStringMap(selector, "matchLabels"); ok {
+			selector["matchLabels"], err = processor.SelectorLabels(matchLabels)
+			if err != nil {
+				return true, nil, err
+			}
+		}
+		res.data.Selector, err = marshalFields(map[string]interface{}{"selector": selector})
+		if err != nil {
+			return true, nil, err
+		}
+	}
+
+	// a rollout either embeds a pod template or references an existing workload with workloadRef.
+	if tpl, ok := spec["template"].(map[string]interface{}); ok {
+		podTemplate := corev1.PodTemplateSpec{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(tpl, &podTemplate)
+		if err != nil {
+			return true, nil, fmt.Errorf("%w: unable to cast to pod template", err)
+		}
+		format.QuoteStringsInStruct(&podTemplate)
+
+		res.data.PodLabels, err = processor.SelectorLabels(podTemplate.ObjectMeta.Labels)
+		if err != nil {
+			return true, nil, err
+		}
+		if len(podTemplate.ObjectMeta.Annotations) != 0 {
+			podAnnotations, err := cue.Marshal(map[string]interface{}{"annotations": podTemplate.ObjectMeta.Annotations}, 6, true)
+			if err != nil {
+				return true, nil, err
+			}
+			res.data.PodAnnotations = "\n" + podAnnotations
+		}
+
+		// collect references before pod spec processing replaces names with templated ones.
+		res.configRefs = pod.ConfigRefs(podTemplate.Spec)
+		rawPodSpec, _, _ := unstructured.NestedMap(tpl, "spec")
+		specMap, podValues, err := pod.ProcessSpec(nameCamel, appMeta, podTemplate.Spec, podTemplate.ObjectMeta.Labels, rawPodSpec)
+		if err != nil {
+			return true, nil, err
+		}
+		err = values.Merge(podValues)
+		if err != nil {
+			return true, nil, err
+		}
+		podSpec, err := cue.Marshal(specMap, 6, true)
+		if err != nil {
+			return true, nil, err
+		}
+		res.data.Spec = strings.ReplaceAll(podSpec, "'", "")
+	}
+
+	rest := map[string]interface{}{}
+	for k, v := range spec {
+		switch k {
+		case "replicas", "revisionHistoryLimit", "selector", "strategy", "template":
+			continue
+		}
+		rest[k] = v
+	}
+	if len(rest) != 0 {
+		format.QuoteStringsInStruct(&rest)
+		if workloadRef, ok := rest["workloadRef"].(map[string]interface{}); ok {
+			workloadRef["name"] = processor.TemplatedQuotedName(appMeta, workloadRef["name"])
+		}
+		res.data.Rest, err = marshalFields(rest)
+		if err != nil {
+			return true, nil, err
+		}
+	}
+
+	return true, res, nil
+}
+
+func processReplicas(name string, spec map[string]interface{}, values *timonify.Values) (string, error) {
+	replicas, ok, err := unstructured.NestedInt64(spec, "replicas")
+	if err != nil || !ok {
+		return "", err
+	}
+	replicasTpl, err := values.Add(cue.MustParse(processor.ReplicasSchema), replicas, name, "replicas")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("replicas: %s", replicasTpl), nil
+}
+
+func processRevisionHistoryLimit(name string, spec map[string]interface{}, values *timonify.Values) (string, error) {
+	limit, ok, err := unstructured.NestedInt64(spec, "revisionHistoryLimit")
+	if err != nil || !ok {
+		return "", err
+	}
+	limitTpl, err := values.Add(ast.NewIdent(processor.RevisionHistoryLimitSchema), limit, name, "revisionHistoryLimit")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("revisionHistoryLimit: %s", limitTpl), nil
+}
+
+// processStrategy exposes canary steps, analysis references and blue-green promotion settings in #Config.
+// Steps and analysis may reference AnalysisTemplates shipped within the module, which names depend on the
+// instance name, so their defaults are kept in the #Config schema instead of values.cue.
+func processStrategy(name string, appMeta timonify.AppMetadata, spec map[string]interface{}, values *timonify.Values) (string, error) {
+	strategy, ok, err := unstructured.NestedMap(spec, "strategy")
+	if err != nil || !ok {
+		return "", err
+	}
+	format.QuoteStringsInStruct(&strategy)
+
+	if canary, ok := strategy["canary"].(map[string]interface{}); ok {
+		templateServiceNames(appMeta, canary, "canaryService", "stableService")
+		if steps, ok := canary["steps"].([]interface{}); ok {
+			for _, step := range steps {
+				if step, ok := step.(map[string]interface{}); ok {
+					if analysis, ok := step["analysis"].(map[string]interface{}); ok {
+						templateAnalysisNames(appMeta, analysis)
+					}
+				}
+			}
+			ref, err := addDefault(values, stepsSchema, steps, name, "strategy", "canary", "steps")
+			if err != nil {
+				return "", err
+			}
+			canary["steps"] = ref
+		}
+		if analysis, ok := canary["analysis"].(map[string]interface{}); ok {
+			templateAnalysisNames(appMeta, analysis)
+			ref, err := addDefault(values, analysisSchema, analysis, name, "strategy", "canary", "analysis")
+			if err != nil {
+				return "", err
+			}
+			canary["analysis"] = ref
+		}
+	}
+
+	if blueGreen, ok := strategy["blueGreen"].(map[string]interface{}); ok {
+		templateServiceNames(appMeta, blueGreen, "activeService", "previewService")
+		for _, key := range []string{"prePromotionAnalysis", "postPromotionAnalysis"} {
+			analysis, ok := blueGreen[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			templateAnalysisNames(appMeta, analysis)
+			ref, err := addDefault(values, analysisSchema, analysis, name, "strategy", "blueGreen", key)
+			if err != nil {
+				return "", err
+			}
+			blueGreen[key] = ref
+		}
+		if autoPromotion, ok := blueGreen["autoPromotionEnabled"].(bool); ok {
+			ref, err := values.Add(ast.NewIdent("bool"), autoPromotion, name, "strategy", "blueGreen", "autoPromotionEnabled")
+			if err != nil {
+				return "", err
+			}
+			blueGreen["autoPromotionEnabled"] = ref
+		}
+	}
+
+	return marshalFields(map[string]interface{}{"strategy": strategy})
+}
+
+// marshalFields marshals given fields without enclosing braces.
+func marshalFields(fields map[string]interface{}) (string, error) {
+	res, err := cue.Marshal(fields, 0, true)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(res, "{}"), nil
+}
+
+// addDefault adds config field with the given value as a default: *value | schema.
+func addDefault(values *timonify.Values, schema string, value interface{}, name ...string) (string, error) {
+	def, err := cue.Marshal(value, 0, true)
+	if err != nil {
+		return "", err
+	}
+	config := &ast.BinaryExpr{
+		Op: token.OR,
+		X:  &ast.UnaryExpr{Op: token.MUL, X: configRelative(cue.MustParse(def))},
+		Y:  cue.MustParse(schema),
+	}
+	if err = values.AddConfig(config, false, name...); err != nil {
+		return "", fmt.Errorf("%w: unable to set rollout config field", err)
+	}
+	return "#config." + strings.Join(name, "."), nil
+}
+
+// configRelative replaces #config.<field> references of templated names with <field>, objects refer to #config,
+// but defaults live inside #Config itself.
+func configRelative(expr ast.Expr) ast.Expr {
+	return astutil.Apply(expr, func(c astutil.Cursor) bool {
+		if sel, ok := c.Node().(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "#config" {
+				if label, ok := sel.Sel.(*ast.Ident); ok {
+					c.Replace(ast.NewIdent(label.Name))
+				}
+				return false
+			}
+		}
+		return true
+	}, nil).(ast.Expr)
+}
+
+func templateServiceNames(appMeta timonify.AppMetadata, strategy map[string]interface{}, keys ...string) {
+	for _, key := range keys {
+		if svc, ok := strategy[key]; ok {
+			strategy[key] = processor.TemplatedQuotedName(appMeta, svc)
+		}
+	}
+}
+
+func templateAnalysisNames(appMeta timonify.AppMetadata, analysis map[string]interface{}) {
+	templates, ok := analysis["templates"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, t := range templates {
+		ref, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// cluster scoped templates are not part of the module
+		if clusterScope, _ := ref["clusterScope"].(bool); clusterScope {
+			continue
+		}
+		if templateName, ok := ref["templateName"]; ok {
+			ref["templateName"] = processor.TemplatedQuotedName(appMeta, templateName)
+		}
+	}
+}
+
+type result struct {
+	name string
+	data struct {
+		Type                 string
+		Meta                 string
+		Replicas             string
+		RevisionHistoryLimit string
+		Selector             string
+		Strategy             string
+		Rest                 string
+		PodLabels            string
+		PodAnnotations       string
+		Spec                 string
+		Checksums            string
+		ChecksumImports      string
+	}
+	values     *timonify.Values
+	appMeta    timonify.AppMetadata
+	configRefs []pod.ObjectRef
+}
+
+func (r *result) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + "Rollout"
+}
+
+func (r *result) Filename() string {
+	return r.name + "-rollout.cue"
+}
+
+func (r *result) Values() *timonify.Values {
+	return r.values
+}
+
+func (r *result) Write(writer io.Writer) error {
+	// module ConfigMaps and Secrets are known only after all objects are processed.
+	data := r.data
+	checksums, err := pod.ChecksumAnnotations(r.appMeta, r.configRefs)
+	if err != nil {
+		return err
+	}
+	if checksums != "" {
+		data.Checksums = checksums
+		data.ChecksumImports = pod.ChecksumImports
+	}
+	var buf bytes.Buffer
+	if err := rolloutTempl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+func (r *result) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *result) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + "Rollout")
+}
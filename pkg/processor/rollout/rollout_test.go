@@ -0,0 +1,134 @@
+package rollout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+)
+
+const (
+	strCanary = `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: my-app-rollout
+  namespace: my-ns
+spec:
+  replicas: 5
+  revisionHistoryLimit: 2
+  selector:
+    matchLabels:
+      app: my-app
+  template:
+    metadata:
+      labels:
+        app: my-app
+    spec:
+      containers:
+      - name: app
+        image: argoproj/rollouts-demo:blue
+  strategy:
+    canary:
+      canaryService: my-app-canary
+      stableService: my-app-stable
+      analysis:
+        templates:
+        - templateName: my-app-success-rate
+        startingStep: 2
+      steps:
+      - setWeight: 20
+      - pause: {duration: 1h}
+      - analysis:
+          templates:
+          - templateName: cluster-smoke
+            clusterScope: true
+`
+	strCanaryAnalysis = `apiVersion: argoproj.io/v1alpha1
+kind: AnalysisTemplate
+metadata:
+  name: my-app-success-rate
+  namespace: my-ns
+spec:
+  metrics:
+  - name: success-rate
+`
+	strBlueGreen = `apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: my-app-rollout
+  namespace: my-ns
+spec:
+  workloadRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: my-app-deployment
+  strategy:
+    blueGreen:
+      activeService: my-app-active
+      previewService: my-app-preview
+      autoPromotionEnabled: false
+      prePromotionAnalysis:
+        templates:
+        - templateName: smoke
+`
+)
+
+func Test_rollout_Process(t *testing.T) {
+	var testInstance rollout
+
+	t.Run("canary", func(t *testing.T) {
+		obj := internal.GenerateObj(strCanary)
+		testMeta := metadata.New(config.Config{ModuleName: "module-name"})
+		testMeta.Load(obj)
+		testMeta.Load(internal.GenerateObj(strCanaryAnalysis))
+		processed, tpl, err := testInstance.Process(testMeta, obj)
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "#RolloutRollout: {")
+		assert.Contains(t, out, "replicas: #config.rollout.replicas")
+		assert.Contains(t, out, "steps: #config.rollout.strategy.canary.steps")
+		assert.Contains(t, out, "analysis: #config.rollout.strategy.canary.analysis")
+		assert.Contains(t, out, "image: #config.rollout.app.image.reference")
+		assert.Contains(t, out, "spec: corev1.#PodSpec & {")
+
+		assert.Equal(t, int64(5), tpl.Values().Values["rollout"].(map[string]interface{})["replicas"])
+		cfg, err := format.Node(tpl.Values().Config)
+		assert.NoError(t, err)
+		// module analysis templates are templated relatively to #Config, cluster scoped ones are kept as is
+		assert.Contains(t, string(cfg), `templateName: metadata.name + "-success-rate"`)
+		assert.Contains(t, string(cfg), `templateName: "cluster-smoke"`)
+		assert.Contains(t, string(cfg), `startingStep: 2`)
+		// workload schemas are shared with Deployment
+		assert.Contains(t, string(cfg), `replicas:             *1 | int & >=0`)
+		assert.Contains(t, string(cfg), `revisionHistoryLimit: int64`)
+	})
+	t.Run("blue-green with workload reference", func(t *testing.T) {
+		obj := internal.GenerateObj(strBlueGreen)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.True(t, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "prePromotionAnalysis: #config.myAppRollout.strategy.blueGreen.prePromotionAnalysis")
+		assert.Contains(t, out, "autoPromotionEnabled: #config.myAppRollout.strategy.blueGreen.autoPromotionEnabled")
+		assert.Contains(t, out, `name: "my-app-deployment"`)
+		assert.NotContains(t, out, "template:")
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
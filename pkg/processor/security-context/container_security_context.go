@@ -2,9 +2,9 @@ package security_context
 
 import (
 	"fmt"
-	"github.com/syndicut/timonify/pkg/cue"
 
-	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/cue"
 	"github.com/syndicut/timonify/pkg/timonify"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -12,17 +12,41 @@ import (
 const (
 	sc             = "securityContext"
 	cscValueName   = "containerSecurityContext"
+	pscValueName   = "podSecurityContext"
 	timoniTemplate = "#config.%[1]s.%[2]s.containerSecurityContext"
+	podTemplate    = "#config.%[1]s.podSecurityContext"
 )
 
-// ProcessContainerSecurityContext adds 'securityContext' to the podSpec in specMap, if it doesn't have one already defined.
-func ProcessContainerSecurityContext(nameCamel string, specMap map[string]interface{}, values *timonify.Values) error {
-	err := processSecurityContext(nameCamel, "containers", specMap, values)
+// ProcessPodSecurityContext adds pod 'securityContext' to the #Config constrained by Pod Security Standard profile.
+// With privileged profile securityContext is parametrized only if it is defined in the podSpec.
+func ProcessPodSecurityContext(nameCamel string, profile string, specMap map[string]interface{}, values *timonify.Values) error {
+	podSecurityContext, defined := specMap[sc]
+	if !defined || podSecurityContext == nil {
+		if profile == config.PodSecurityPrivileged || profile == "" {
+			return nil
+		}
+		podSecurityContext = map[string]interface{}{}
+	}
+	schema, err := podSchema(profile)
 	if err != nil {
 		return err
 	}
+	_, err = values.Add(cue.MustParse(schema), podSecurityContext, nameCamel, pscValueName)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(specMap, fmt.Sprintf(podTemplate, nameCamel), sc)
+}
 
-	err = processSecurityContext(nameCamel, "initContainers", specMap, values)
+// ProcessContainerSecurityContext adds containers 'securityContext' to the #Config constrained by Pod Security Standard profile.
+// With privileged profile securityContext is parametrized only for containers having one already defined.
+func ProcessContainerSecurityContext(nameCamel string, profile string, specMap map[string]interface{}, values *timonify.Values) error {
+	err := processSecurityContext(nameCamel, profile, "containers", specMap, values)
+	if err != nil {
+		return err
+	}
+
+	err = processSecurityContext(nameCamel, profile, "initContainers", specMap, values)
 	if err != nil {
 		return err
 	}
@@ -30,16 +54,21 @@ func ProcessContainerSecurityContext(nameCamel string, specMap map[string]interf
 	return nil
 }
 
-func processSecurityContext(nameCamel string, containerType string, specMap map[string]interface{}, values *timonify.Values) error {
+func processSecurityContext(nameCamel string, profile string, containerType string, specMap map[string]interface{}, values *timonify.Values) error {
 	if containers, defined := specMap[containerType]; defined {
+		keys := timonify.ContainerKeys(specMap)
 		for _, container := range containers.([]interface{}) {
 			castedContainer := container.(map[string]interface{})
-			containerName := strcase.ToLowerCamel(castedContainer["name"].(string))
-			if _, defined2 := castedContainer["securityContext"]; defined2 {
-				err := setSecContextValue(nameCamel, containerName, castedContainer, values)
-				if err != nil {
-					return err
+			containerName := keys.ContainerKey(castedContainer)
+			if castedContainer[sc] == nil {
+				if profile == config.PodSecurityPrivileged || profile == "" {
+					continue
 				}
+				castedContainer[sc] = map[string]interface{}{}
+			}
+			err := setSecContextValue(nameCamel, containerName, profile, castedContainer, values)
+			if err != nil {
+				return err
 			}
 		}
 		err := unstructured.SetNestedField(specMap, containers, containerType)
@@ -50,16 +79,13 @@ func processSecurityContext(nameCamel string, containerType string, specMap map[
 	return nil
 }
 
-func setSecContextValue(resourceName string, containerName string, castedContainer map[string]interface{}, values *timonify.Values) error {
-	if castedContainer["securityContext"] != nil {
-		securityContextSchema := cue.MustParse(`corev1.#SecurityContext & {
-	allowPrivilegeEscalation: false
-	capabilities:
-	{
-		drop: *["ALL"] | [string]
-	}
-}`)
-		_, err := values.Add(securityContextSchema, castedContainer["securityContext"], resourceName, containerName, cscValueName)
+func setSecContextValue(resourceName string, containerName string, profile string, castedContainer map[string]interface{}, values *timonify.Values) error {
+	if castedContainer[sc] != nil {
+		schema, err := containerSchema(profile)
+		if err != nil {
+			return err
+		}
+		_, err = values.Add(cue.MustParse(schema), castedContainer[sc], resourceName, containerName, cscValueName)
 		if err != nil {
 			return err
 		}
@@ -3,34 +3,37 @@ package security_context
 import (
 	"testing"
 
+	cueformat "cuelang.org/go/cue/format"
 	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/pkg/config"
 	"github.com/syndicut/timonify/pkg/timonify"
 )
 
 func TestProcessContainerSecurityContext(t *testing.T) {
 	type args struct {
 		nameCamel string
+		profile   string
 		specMap   map[string]interface{}
-		values    *timonify.Values
 	}
 	tests := []struct {
 		name string
 		args args
-		want *timonify.Values
+		want map[string]interface{}
 	}{
 		{
 			name: "test with empty specMap",
 			args: args{
 				nameCamel: "someResourceName",
+				profile:   config.PodSecurityPrivileged,
 				specMap:   map[string]interface{}{},
-				values:    &timonify.NewValues(),
 			},
-			want: &timonify.NewValues(),
+			want: map[string]interface{}{},
 		},
 		{
 			name: "test with single container",
 			args: args{
 				nameCamel: "someResourceName",
+				profile:   config.PodSecurityPrivileged,
 				specMap: map[string]interface{}{
 					"containers": []interface{}{
 						map[string]interface{}{
@@ -41,9 +44,8 @@ func TestProcessContainerSecurityContext(t *testing.T) {
 						},
 					},
 				},
-				values: &timonify.NewValues(),
 			},
-			want: &timonify.Values{
+			want: map[string]interface{}{
 				"someResourceName": map[string]interface{}{
 					"someContainerName": map[string]interface{}{
 						"containerSecurityContext": map[string]interface{}{
@@ -57,6 +59,7 @@ func TestProcessContainerSecurityContext(t *testing.T) {
 			name: "test with multiple containers",
 			args: args{
 				nameCamel: "someResourceName",
+				profile:   config.PodSecurityPrivileged,
 				specMap: map[string]interface{}{
 					"containers": []interface{}{
 						map[string]interface{}{
@@ -71,11 +74,13 @@ func TestProcessContainerSecurityContext(t *testing.T) {
 								"allowPrivilegeEscalation": true,
 							},
 						},
+						map[string]interface{}{
+							"name": "ThirdContainer",
+						},
 					},
 				},
-				values: &timonify.NewValues(),
 			},
-			want: &timonify.Values{
+			want: map[string]interface{}{
 				"someResourceName": map[string]interface{}{
 					"firstContainer": map[string]interface{}{
 						"containerSecurityContext": map[string]interface{}{
@@ -90,58 +95,186 @@ func TestProcessContainerSecurityContext(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test restricted profile exposes containers without securityContext",
+			args: args{
+				nameCamel: "someResourceName",
+				profile:   config.PodSecurityRestricted,
+				specMap: map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name": "SomeContainerName",
+						},
+					},
+				},
+			},
+			want: map[string]interface{}{
+				"someResourceName": map[string]interface{}{
+					"someContainerName": map[string]interface{}{
+						"containerSecurityContext": map[string]interface{}{},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ProcessContainerSecurityContext(tt.args.nameCamel, tt.args.specMap, tt.args.values)
-			assert.Equal(t, tt.want, tt.args.values)
+			values := timonify.NewValues()
+			err := ProcessContainerSecurityContext(tt.args.nameCamel, tt.args.profile, tt.args.specMap, values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, values.Values)
 		})
 	}
 }
 
 func Test_setSecContextValue(t *testing.T) {
 	type args struct {
-		resourceName            string
-		containerName           string
-		castedContainer         map[string]interface{}
-		values                  *timonify.Values
-		fieldName               string
-		useRenderedHelmTemplate bool
+		resourceName    string
+		containerName   string
+		profile         string
+		castedContainer map[string]interface{}
 	}
 	tests := []struct {
-		name string
-		args args
-		want *timonify.Values
+		name          string
+		args          args
+		want          map[string]interface{}
+		wantContainer map[string]interface{}
 	}{
 		{
 			name: "simple test with single container and single value",
 			args: args{
 				resourceName:  "someResource",
 				containerName: "someContainer",
+				profile:       config.PodSecurityPrivileged,
 				castedContainer: map[string]interface{}{
 					"securityContext": map[string]interface{}{
-						"someField": "someValue",
+						"someField": "\"someValue\"",
 					},
 				},
-				values:                  &timonify.NewValues(),
-				fieldName:               "someField",
-				useRenderedHelmTemplate: false,
 			},
-			want: &timonify.Values{
+			want: map[string]interface{}{
 				"someResource": map[string]interface{}{
 					"someContainer": map[string]interface{}{
 						"containerSecurityContext": map[string]interface{}{
-							"someField": "someValue",
+							"someField": "\"someValue\"",
 						},
 					},
 				},
 			},
+			wantContainer: map[string]interface{}{
+				"securityContext": "#config.someResource.someContainer.containerSecurityContext",
+			},
+		},
+		{
+			name: "container without securityContext",
+			args: args{
+				resourceName:    "someResource",
+				containerName:   "someContainer",
+				profile:         config.PodSecurityPrivileged,
+				castedContainer: map[string]interface{}{},
+			},
+			want:          map[string]interface{}{},
+			wantContainer: map[string]interface{}{},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			setSecContextValue(tt.args.resourceName, tt.args.containerName, tt.args.castedContainer, tt.args.values)
-			assert.Equal(t, tt.want, tt.args.values)
+			values := timonify.NewValues()
+			err := setSecContextValue(tt.args.resourceName, tt.args.containerName, tt.args.profile, tt.args.castedContainer, values)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, values.Values)
+			assert.Equal(t, tt.wantContainer, tt.args.castedContainer)
 		})
 	}
 }
+
+func TestProcessContainerSecurityContext_profile(t *testing.T) {
+	specMap := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app"},
+		},
+	}
+	values := timonify.NewValues()
+	err := ProcessContainerSecurityContext("web", config.PodSecurityRestricted, specMap, values)
+	assert.NoError(t, err)
+	assert.Equal(t, "#config.web.app.containerSecurityContext",
+		specMap["containers"].([]interface{})[0].(map[string]interface{})["securityContext"])
+
+	cfg, err := cueformat.Node(values.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cfg), "allowPrivilegeEscalation: false")
+	assert.Contains(t, string(cfg), `*["ALL"] | ["ALL", ...string]`)
+}
+
+func TestProcessContainerSecurityContext_clashingNames(t *testing.T) {
+	specMap := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": `"app-1"`, "securityContext": map[string]interface{}{}},
+			map[string]interface{}{"name": `"app1"`, "securityContext": map[string]interface{}{}},
+		},
+	}
+	values := timonify.NewValues()
+	err := ProcessContainerSecurityContext("web", config.PodSecurityPrivileged, specMap, values)
+	assert.NoError(t, err)
+	// keys match the ones of other container values
+	assert.Equal(t, `#config.web."app-1".containerSecurityContext`,
+		specMap["containers"].([]interface{})[0].(map[string]interface{})["securityContext"])
+	assert.Contains(t, values.Values["web"], "app1")
+	assert.Contains(t, values.Values["web"], "app-1")
+}
+
+func TestProcessPodSecurityContext(t *testing.T) {
+	t.Run("privileged profile without securityContext", func(t *testing.T) {
+		specMap := map[string]interface{}{}
+		values := timonify.NewValues()
+		err := ProcessPodSecurityContext("web", config.PodSecurityPrivileged, specMap, values)
+		assert.NoError(t, err)
+		assert.Empty(t, values.Values)
+		assert.NotContains(t, specMap, "securityContext")
+	})
+	t.Run("privileged profile with securityContext", func(t *testing.T) {
+		specMap := map[string]interface{}{
+			"securityContext": map[string]interface{}{"runAsUser": int64(1000)},
+		}
+		values := timonify.NewValues()
+		err := ProcessPodSecurityContext("web", config.PodSecurityPrivileged, specMap, values)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"web": map[string]interface{}{
+				"podSecurityContext": map[string]interface{}{"runAsUser": int64(1000)},
+			},
+		}, values.Values)
+		assert.Equal(t, "#config.web.podSecurityContext", specMap["securityContext"])
+
+		cfg, err := cueformat.Node(values.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "podSecurityContext: corev1.#PodSecurityContext")
+	})
+	t.Run("baseline profile without securityContext", func(t *testing.T) {
+		specMap := map[string]interface{}{}
+		values := timonify.NewValues()
+		err := ProcessPodSecurityContext("web", config.PodSecurityBaseline, specMap, values)
+		assert.NoError(t, err)
+		assert.Equal(t, "#config.web.podSecurityContext", specMap["securityContext"])
+
+		cfg, err := cueformat.Node(values.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), `"RuntimeDefault" | "Localhost"`)
+		assert.NotContains(t, string(cfg), "runAsNonRoot")
+	})
+	t.Run("restricted profile", func(t *testing.T) {
+		specMap := map[string]interface{}{}
+		values := timonify.NewValues()
+		err := ProcessPodSecurityContext("web", config.PodSecurityRestricted, specMap, values)
+		assert.NoError(t, err)
+
+		cfg, err := cueformat.Node(values.Config)
+		assert.NoError(t, err)
+		assert.Contains(t, string(cfg), "runAsNonRoot: true")
+		assert.Contains(t, string(cfg), `type: *"RuntimeDefault" | "Localhost"`)
+	})
+	t.Run("unknown profile", func(t *testing.T) {
+		err := ProcessPodSecurityContext("web", "strict", map[string]interface{}{}, timonify.NewValues())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,69 @@
+package security_context
+
+import (
+	"fmt"
+
+	"github.com/syndicut/timonify/pkg/config"
+)
+
+// Pod Security Standards constraints, see https://kubernetes.io/docs/concepts/security/pod-security-standards/.
+const (
+	// baselineCommon - fields restricted by the baseline profile on both pod and container level.
+	baselineCommon = `
+	seLinuxOptions?: {
+		type?: "" | "container_t" | "container_init_t" | "container_kvm_t"
+		user?:  _|_
+		role?:  _|_
+	}
+	seccompProfile?: type: "RuntimeDefault" | "Localhost"
+	windowsOptions?: hostProcess?: false`
+	baselineCapabilities = `
+	capabilities?: add?: [...("AUDIT_WRITE" | "CHOWN" | "DAC_OVERRIDE" | "FOWNER" | "FSETID" | "KILL" | "MKNOD" | "NET_BIND_SERVICE" | "SETFCAP" | "SETGID" | "SETPCAP" | "SETUID" | "SYS_CHROOT")]`
+	baselineContainer = baselineCommon + `
+	privileged?: false
+	procMount?:  "Default"`
+	baselinePod = baselineCommon + `
+	sysctls?: [...{
+		name: "kernel.shm_rmid_forced" | "net.ipv4.ip_local_port_range" | "net.ipv4.ip_unprivileged_port_start" | "net.ipv4.tcp_syncookies" | "net.ipv4.ping_group_range" | "net.ipv4.ip_local_reserved_ports" | "net.ipv4.tcp_keepalive_time" | "net.ipv4.tcp_fin_timeout" | "net.ipv4.tcp_keepalive_intvl" | "net.ipv4.tcp_keepalive_probes"
+	}]`
+	// restricted profile requires seccomp profile and non-root user on pod level,
+	// containers may only override them with allowed values.
+	restrictedContainer = baselineContainer + `
+	allowPrivilegeEscalation: false
+	runAsNonRoot?: true
+	runAsUser?:    int & !=0
+	capabilities: {
+		drop: *["ALL"] | ["ALL", ...string]
+		add?: [..."NET_BIND_SERVICE"]
+	}`
+	restrictedPod = baselinePod + `
+	runAsNonRoot: true
+	runAsUser?:   int & !=0
+	seccompProfile: type: *"RuntimeDefault" | "Localhost"`
+)
+
+// containerSchema returns container securityContext schema enforcing given Pod Security Standard profile.
+func containerSchema(profile string) (string, error) {
+	switch profile {
+	case config.PodSecurityPrivileged, "":
+		return "corev1.#SecurityContext", nil
+	case config.PodSecurityBaseline:
+		return "corev1.#SecurityContext & {" + baselineContainer + baselineCapabilities + "\n}", nil
+	case config.PodSecurityRestricted:
+		return "corev1.#SecurityContext & {" + restrictedContainer + "\n}", nil
+	}
+	return "", fmt.Errorf("unknown pod security profile %s", profile)
+}
+
+// podSchema returns pod securityContext schema enforcing given Pod Security Standard profile.
+func podSchema(profile string) (string, error) {
+	switch profile {
+	case config.PodSecurityPrivileged, "":
+		return "corev1.#PodSecurityContext", nil
+	case config.PodSecurityBaseline:
+		return "corev1.#PodSecurityContext & {" + baselinePod + "\n}", nil
+	case config.PodSecurityRestricted:
+		return "corev1.#PodSecurityContext & {" + restrictedPod + "\n}", nil
+	}
+	return "", fmt.Errorf("unknown pod security profile %s", profile)
+}
@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"github.com/syndicut/timonify/pkg/cue"
+)
+
+const (
+	selectorLabels = "#config.selector.labels"
+	// nameLabel - selector label provided by Timoni with the instance name.
+	nameLabel = "app.kubernetes.io/name"
+)
+
+// SelectorLabels - returns labels bound to the instance selector labels from #config.selector.labels,
+// so module instances in the same namespace do not select each other's pods. Given labels, which are
+// quoted strings, are kept as extra labels.
+func SelectorLabels(labels map[string]string) (string, error) {
+	extra := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k != nameLabel {
+			extra[k] = v
+		}
+	}
+	if len(extra) == 0 {
+		return selectorLabels, nil
+	}
+	res, err := cue.Marshal(extra, 0, true)
+	if err != nil {
+		return "", err
+	}
+	return selectorLabels + " & " + res, nil
+}
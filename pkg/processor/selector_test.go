@@ -0,0 +1,22 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorLabels(t *testing.T) {
+	t.Run("no extra labels", func(t *testing.T) {
+		res, err := SelectorLabels(map[string]string{"app.kubernetes.io/name": `"my-app"`})
+		assert.NoError(t, err)
+		assert.Equal(t, "#config.selector.labels", res)
+	})
+	t.Run("extra labels", func(t *testing.T) {
+		res, err := SelectorLabels(map[string]string{"app.kubernetes.io/name": `"my-app"`, "app": `"web"`})
+		assert.NoError(t, err)
+		assert.Equal(t, `#config.selector.labels & {
+	app: "web"
+}`, res)
+	})
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var ingressTempl, _ = template.New("ingress").Parse(
+	`package templates
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+{{ .Type }}: networkingv1.#Ingress & {
+	#config:    #Config
+{{ .Meta }}
+	spec: networkingv1.#IngressSpec & {{ .Spec }}
+}`)
+
+var ingressGVC = schema.GroupVersionKind{
+	Group:   "networking.k8s.io",
+	Version: "v1",
+	Kind:    "Ingress",
+}
+
+// NewIngress creates processor for k8s Ingress resource.
+func NewIngress() timonify.Processor {
+	return &ingress{}
+}
+
+type ingress struct{}
+
+// Process k8s Ingress object into template. Returns false if not capable of processing given resource type.
+func (r ingress) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind() != ingressGVC {
+		return false, nil, nil
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get ingress spec", err)
+	}
+	format.QuoteStringsInStruct(&spec)
+	if tls, ok := spec["tls"].([]interface{}); ok {
+		for _, t := range tls {
+			if t, ok := t.(map[string]interface{}); ok {
+				t["secretName"] = processor.TemplatedQuotedName(appMeta, t["secretName"])
+			}
+		}
+	}
+
+	res := &ingressResult{
+		name:    appMeta.TrimName(obj.GetName()),
+		appMeta: appMeta,
+		spec:    spec,
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	return true, res, nil
+}
+
+// processBackend templates backend service name, service port is kept as is.
+func processBackend(appMeta timonify.AppMetadata, backend interface{}) {
+	b, ok := backend.(map[string]interface{})
+	if !ok {
+		return
+	}
+	svc, ok := b["service"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	name, _ := svc["name"].(string)
+	svc["name"] = processor.TemplatedQuotedName(appMeta, name)
+}
+
+type ingressResult struct {
+	name    string
+	appMeta timonify.AppMetadata
+	spec    map[string]interface{}
+	data    struct {
+		Type string
+		Meta string
+		Spec string
+	}
+}
+
+func (r *ingressResult) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + "Ingress"
+}
+
+func (r *ingressResult) Filename() string {
+	return r.name + "-ingress.cue"
+}
+
+func (r *ingressResult) Values() *timonify.Values {
+	return timonify.NewValues()
+}
+
+// Write templates backend service names.
+func (r *ingressResult) Write(writer io.Writer) error {
+	spec := runtime.DeepCopyJSON(r.spec)
+	processBackend(r.appMeta, spec["defaultBackend"])
+	rules, _ := spec["rules"].([]interface{})
+	for _, rule := range rules {
+		paths, _, _ := unstructured.NestedSlice(rule.(map[string]interface{}), "http", "paths")
+		for _, path := range paths {
+			processBackend(r.appMeta, path.(map[string]interface{})["backend"])
+		}
+		if len(paths) != 0 {
+			_ = unstructured.SetNestedSlice(rule.(map[string]interface{}), paths, "http", "paths")
+		}
+	}
+	var err error
+	r.data.Spec, err = cue.Marshal(spec, 0, true)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = ingressTempl.Execute(&buf, r.data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+func (r *ingressResult) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *ingressResult) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + "Ingress")
+}
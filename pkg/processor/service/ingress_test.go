@@ -1,9 +1,13 @@
 package service
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/syndicut/timonify/pkg/config"
 	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/timonify"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/syndicut/timonify/internal"
@@ -36,6 +40,28 @@ func Test_ingress_Process(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, true, processed)
 	})
+	t.Run("backend service name templated", func(t *testing.T) {
+		obj := internal.GenerateObj(ingressYaml)
+		appMeta := metadata.New(config.Config{ModuleName: "myapp"})
+		appMeta.Load(obj)
+		appMeta.Load(internal.GenerateObj(`apiVersion: v1
+kind: Service
+metadata:
+  name: myapp-service`))
+		appMeta.AddContainerPorts(map[string]string{"app": "myapp"}, []timonify.ContainerPort{
+			{Port: 8443, Reference: "#config.app.app.ports.port8443"},
+		})
+		processed, tpl, err := testInstance.Process(appMeta, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, `name: #config.metadata.name + "-service"`)
+		// service port is not linked to the container port, it is the service contract
+		assert.Contains(t, out, "number: 8443")
+	})
 	t.Run("skipped", func(t *testing.T) {
 		obj := internal.TestNs
 		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
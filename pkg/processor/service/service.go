@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+
+	"cuelang.org/go/cue/ast"
+	cueformat "cuelang.org/go/cue/format"
+	"github.com/iancoleman/strcase"
+	"github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/format"
+	"github.com/syndicut/timonify/pkg/processor"
+	"github.com/syndicut/timonify/pkg/timonify"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var svcTempl, _ = template.New("service").Parse(
+	`package templates
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+{{ .Type }}: corev1.#Service & {
+	#config:    #Config
+{{ .Meta }}
+	spec: corev1.#ServiceSpec & {{ .Spec }}
+}`)
+
+var svcGVC = schema.GroupVersionKind{
+	Group:   "",
+	Version: "v1",
+	Kind:    "Service",
+}
+
+// New creates processor for k8s Service resource.
+func New() timonify.Processor {
+	return &svc{}
+}
+
+type svc struct{}
+
+// Process k8s Service object into template. Returns false if not capable of processing given resource type.
+func (r svc) Process(appMeta timonify.AppMetadata, obj *unstructured.Unstructured) (bool, timonify.Template, error) {
+	if obj.GroupVersionKind() != svcGVC {
+		return false, nil, nil
+	}
+	service := corev1.Service{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &service)
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to cast to service", err)
+	}
+	meta, err := processor.ProcessObjMeta(appMeta, obj)
+	if err != nil {
+		return true, nil, err
+	}
+	spec, _, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return true, nil, fmt.Errorf("%w: unable to get service spec", err)
+	}
+	format.QuoteStringsInStruct(&spec)
+
+	res := &result{
+		name:     appMeta.TrimName(obj.GetName()),
+		appMeta:  appMeta,
+		selector: service.Spec.Selector,
+		ports:    service.Spec.Ports,
+		spec:     spec,
+		values:   timonify.NewValues(),
+	}
+	res.data.Type = res.typeName()
+	res.data.Meta = meta
+	return true, res, nil
+}
+
+type result struct {
+	name     string
+	appMeta  timonify.AppMetadata
+	selector map[string]string
+	ports    []corev1.ServicePort
+	spec     map[string]interface{}
+	data     struct {
+		Type string
+		Meta string
+		Spec string
+	}
+	values *timonify.Values
+}
+
+func (r *result) typeName() string {
+	return "#" + strcase.ToCamel(r.name) + "Service"
+}
+
+func (r *result) Filename() string {
+	return r.name + "-service.cue"
+}
+
+func (r *result) Values() *timonify.Values {
+	return r.values
+}
+
+// Write resolves service ports and selector, container ports and pods are registered by workloads processing,
+// so it is done on Write.
+func (r *result) Write(writer io.Writer) error {
+	spec := runtime.DeepCopyJSON(r.spec)
+	if r.appMeta.SelectsModulePods(r.selector) {
+		// select only pods of the same module instance
+		selector, _, _ := unstructured.NestedStringMap(spec, "selector")
+		labels, err := processor.SelectorLabels(selector)
+		if err != nil {
+			return err
+		}
+		spec["selector"] = labels
+	}
+	if ports, ok := spec["ports"].([]interface{}); ok {
+		for i, p := range r.ports {
+			linkPort(r.appMeta, r.selector, p, ports[i].(map[string]interface{}))
+		}
+	}
+	var err error
+	r.data.Spec, err = cue.Marshal(spec, 0, true)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = svcTempl.Execute(&buf, r.data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := cueformat.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format cue: %w", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+// linkPort references container port config in numeric targetPort, service port itself is kept as is.
+func linkPort(appMeta timonify.AppMetadata, selector map[string]string, p corev1.ServicePort, port map[string]interface{}) {
+	target := p.TargetPort
+	if target.Type == intstr.Int && target.IntVal == 0 {
+		target = intstr.FromInt(int(p.Port))
+	}
+	cp, ok := appMeta.ContainerPort(selector, target)
+	if !ok {
+		return
+	}
+	if target.Type == intstr.Int {
+		port["targetPort"] = cp.Reference
+	}
+}
+
+func (r *result) ObjectType() ast.Expr {
+	return ast.NewIdent(r.typeName())
+}
+
+func (r *result) ObjectLabel() ast.Label {
+	return ast.NewIdent(strcase.ToLowerCamel(r.name) + "Service")
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/timonify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+)
+
+const svcYaml = `apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: my-operator-controller-manager-metrics-service
+  namespace: my-operator-system
+spec:
+  ports:
+  - name: https
+    port: 8443
+    targetPort: https
+  - name: metrics
+    port: 80
+    targetPort: 8080
+  - name: other
+    port: 9000
+  selector:
+    control-plane: controller-manager`
+
+func Test_svc_Process(t *testing.T) {
+	var testInstance svc
+
+	t.Run("processed", func(t *testing.T) {
+		obj := internal.GenerateObj(svcYaml)
+		processed, tpl, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		assert.Contains(t, out, "spec: corev1.#ServiceSpec & {")
+		assert.Contains(t, out, "targetPort: 8080")
+		assert.Contains(t, out, `selector: { "control-plane": "controller-manager" }`)
+	})
+	t.Run("ports linked to container ports", func(t *testing.T) {
+		obj := internal.GenerateObj(svcYaml)
+		appMeta := &metadata.Service{}
+		appMeta.AddContainerPorts(map[string]string{"control-plane": "controller-manager", "app": "manager"}, []timonify.ContainerPort{
+			{Name: "https", Port: 8443, Reference: "#config.manager.proxy.ports.https"},
+			{Name: "metrics", Port: 8080, Reference: "#config.manager.manager.ports.metrics"},
+		})
+		processed, tpl, err := testInstance.Process(appMeta, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, true, processed)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tpl.Write(&buf))
+		out := strings.Join(strings.Fields(buf.String()), " ")
+		// named target port follows the container port by name, service port is kept
+		assert.Contains(t, out, `name: "https" port: 8443 targetPort: "https"`)
+		// numeric target port is linked, service port is kept
+		assert.Contains(t, out, `name: "metrics" port: 80 targetPort: #config.manager.manager.ports.metrics`)
+		assert.Contains(t, out, `name: "other" port: 9000`)
+		// selector matches module pods, so it is bound to the instance selector labels
+		assert.Contains(t, out, `selector: #config.selector.labels & { "control-plane": "controller-manager" }`)
+	})
+	t.Run("skipped", func(t *testing.T) {
+		obj := internal.TestNs
+		processed, _, err := testInstance.Process(&metadata.Service{}, obj)
+		assert.NoError(t, err)
+		assert.Equal(t, false, processed)
+	})
+}
@@ -0,0 +1,9 @@
+package processor
+
+const (
+	// ReplicasSchema - #Config schema of workload replicas, shared by workload processors. Workloads may be
+	// scaled to zero.
+	ReplicasSchema = "*1 | int & >=0"
+	// RevisionHistoryLimitSchema - #Config schema of workload revision history limit, shared by workload processors.
+	RevisionHistoryLimitSchema = "int64"
+)
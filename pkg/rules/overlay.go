@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// overlayAny - overlay label selecting every object.
+	overlayAny = "*"
+	// overlayConfig - overlay label extending #Config.
+	overlayConfig = "#Config"
+)
+
+// Overlay - CUE structs unified with processed objects when they are written, with access to #config.
+// Structs are selected by top level labels: "*" for every object, kind or "kind/name", e.g.:
+//
+//	#Config: priority: *"standard" | string
+//	Deployment: spec: template: spec: priorityClassName: #config.priority
+//	"Service/web": metadata: annotations: team: "web"
+//
+// #Config fields are added to the module config.
+type Overlay struct {
+	// objects - overlay sources by selector, sources are parsed for every object to get independent nodes.
+	objects map[string]string
+	config  string
+}
+
+// LoadOverlay - reads and validates CUE overlay file.
+func LoadOverlay(file string) (*Overlay, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read overlay file", err)
+	}
+	f, err := parser.ParseFile(file, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse overlay file", err)
+	}
+	res := &Overlay{objects: map[string]string{}}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.Package:
+			continue
+		case *ast.ImportDecl:
+			return nil, fmt.Errorf("overlay %s: imports are not supported, templates have their own imports", file)
+		case *ast.Field:
+			label, _, err := ast.LabelName(decl.Label)
+			if err != nil {
+				return nil, fmt.Errorf("%w: overlay %s: invalid label", err, file)
+			}
+			if err = validSelector(label); err != nil {
+				return nil, fmt.Errorf("%w: overlay %s", err, file)
+			}
+			if _, ok := decl.Value.(*ast.StructLit); !ok {
+				return nil, fmt.Errorf("overlay %s: %s must be a struct", file, label)
+			}
+			src, err := format.Node(decl.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: overlay %s: %s", err, file, label)
+			}
+			if label == overlayConfig {
+				res.config = string(src)
+				continue
+			}
+			if _, exists := res.objects[label]; exists {
+				return nil, fmt.Errorf("overlay %s: %s is defined more than once", file, label)
+			}
+			res.objects[label] = string(src)
+		default:
+			return nil, fmt.Errorf("overlay %s: only fields selecting objects and #Config are supported", file)
+		}
+	}
+	return res, nil
+}
+
+func validSelector(label string) error {
+	if label == overlayAny || label == overlayConfig {
+		return nil
+	}
+	kind, name, hasName := strings.Cut(label, "/")
+	if kind == "" || hasName && name == "" || strings.HasPrefix(kind, "#") {
+		return fmt.Errorf("invalid selector %q, expected \"*\", kind or \"kind/name\"", label)
+	}
+	return nil
+}
+
+// ApplyOverlay - unifies the object template with overlay structs selecting the original object.
+func ApplyOverlay(overlay *Overlay, obj *unstructured.Unstructured, tmpl timonify.Template) (timonify.Template, error) {
+	var sources []string
+	for _, selector := range []string{overlayAny, obj.GetKind(), obj.GetKind() + "/" + obj.GetName()} {
+		if src, ok := overlay.objects[selector]; ok {
+			sources = append(sources, src)
+		}
+	}
+	if len(sources) == 0 {
+		return tmpl, nil
+	}
+	res := wrap(tmpl)
+	for _, src := range sources {
+		res.overlays = append(res.overlays, cueformat.MustParse(src))
+	}
+	return res, nil
+}
+
+// ApplyOverlayConfig - adds the overlay #Config fields to the template values. Module values are merged from
+// values of all templates, so #Config is applied to one of them, whether overlay structs select any object or not.
+func ApplyOverlayConfig(overlay *Overlay, tmpl timonify.Template) (timonify.Template, error) {
+	if overlay.config == "" {
+		return tmpl, nil
+	}
+	config, ok := cueformat.MustParse(overlay.config).(*ast.StructLit)
+	if !ok {
+		return nil, fmt.Errorf("overlay #Config must be a struct")
+	}
+	res := wrap(tmpl)
+	if err := res.values.Merge(&timonify.Values{Config: config, Values: map[string]interface{}{}}); err != nil {
+		return nil, fmt.Errorf("%w: unable to add overlay #Config", err)
+	}
+	return res, nil
+}
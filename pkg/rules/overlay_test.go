@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/processor"
+)
+
+const overlayCue = `#Config: operator: region: *"eu" | string
+
+"*": metadata: annotations: team: "platform"
+ConfigMap: data: region: #config.operator.region
+"ConfigMap/other": data: other: "true"
+`
+
+func TestLoadOverlay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "overlay.cue")
+	assert.NoError(t, os.WriteFile(file, []byte(overlayCue), 0600))
+	overlay, err := LoadOverlay(file)
+	assert.NoError(t, err)
+	assert.Len(t, overlay.objects, 3)
+	assert.Contains(t, overlay.config, `region: *"eu" | string`)
+
+	for name, invalid := range map[string]string{
+		"syntax error":     "Deployment: {",
+		"import":           "import \"strings\"\nDeployment: metadata: name: strings.ToLower(\"A\")\n",
+		"not a struct":     "Deployment: 1\n",
+		"invalid selector": "\"Deployment/\": spec: replicas: 1\n",
+		"definition":       "#Deployment: spec: replicas: 1\n",
+		"duplicate":        "Deployment: spec: replicas: 1\nDeployment: spec: paused: true\n",
+	} {
+		assert.NoError(t, os.WriteFile(file, []byte(invalid), 0600))
+		_, err = LoadOverlay(file)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApplyOverlay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "overlay.cue")
+	assert.NoError(t, os.WriteFile(file, []byte(overlayCue), 0600))
+	overlay, err := LoadOverlay(file)
+	assert.NoError(t, err)
+
+	obj := internal.GenerateObj(cmYaml)
+	appMeta := metadata.New(config.Config{ModuleName: "module-name"})
+	appMeta.Load(obj)
+	original := obj.DeepCopy()
+	_, tmpl, err := processor.Default().Process(appMeta, obj)
+	assert.NoError(t, err)
+
+	tmpl, err = ApplyOverlay(overlay, original, tmpl)
+	assert.NoError(t, err)
+	cfg, err := format.Node(tmpl.Values().Config)
+	assert.NoError(t, err)
+	// #Config is added to module values separately, see OverlayConfig
+	assert.NotContains(t, string(cfg), "region")
+
+	var out bytes.Buffer
+	assert.NoError(t, tmpl.Write(&out))
+	assert.Contains(t, out.String(), "\n\t{\n\t\tmetadata: annotations: team: \"platform\"\n\t}\n")
+	assert.Contains(t, out.String(), "\n\t{\n\t\tdata: region: #config.operator.region\n\t}\n")
+	assert.NotContains(t, out.String(), "other")
+
+	deployment := internal.GenerateObj(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web`)
+	overlay.objects = map[string]string{"Service": overlay.objects["ConfigMap"]}
+	notMatched, err := ApplyOverlay(overlay, deployment, tmpl)
+	assert.NoError(t, err)
+	assert.Equal(t, tmpl, notMatched)
+}
+
+func TestApplyOverlayConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "overlay.cue")
+	assert.NoError(t, os.WriteFile(file, []byte(`#Config: priority: *"standard" | string`), 0600))
+	overlay, err := LoadOverlay(file)
+	assert.NoError(t, err)
+	assert.Empty(t, overlay.objects)
+
+	obj := internal.GenerateObj(cmYaml)
+	_, tmpl, err := processor.Default().Process(metadata.New(config.Config{ModuleName: "module-name"}), obj)
+	assert.NoError(t, err)
+	res, err := ApplyOverlayConfig(overlay, tmpl)
+	assert.NoError(t, err)
+	cfg, err := format.Node(res.Values().Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cfg), `priority: *"standard" | string`)
+
+	notChanged, err := ApplyOverlayConfig(&Overlay{}, tmpl)
+	assert.NoError(t, err)
+	assert.Equal(t, tmpl, notChanged)
+}
@@ -0,0 +1,230 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+)
+
+// segment - element of the field path: object field, list index or list item selected by its field value.
+type segment struct {
+	field string
+	index int
+	// filterKey and filterValue select list item by its string field, e.g. [?(@.name=="app")].
+	filterKey, filterValue string
+}
+
+func (s segment) isField() bool {
+	return s.field != ""
+}
+
+func (s segment) isFilter() bool {
+	return s.filterKey != ""
+}
+
+// parsePath parses subset of JSONPath: .field, ['field'], [index] and [?(@.field=="value")] segments,
+// optionally wrapped in {} and starting with $.
+func parsePath(path string) ([]segment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimSuffix(strings.TrimPrefix(p, "{"), "}")
+	p = strings.TrimPrefix(p, "$")
+	var res []segment
+	for p != "" {
+		switch {
+		case strings.HasPrefix(p, "."):
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name in path %q", path)
+			}
+			res = append(res, segment{field: p[1 : end+1]})
+			p = p[end+1:]
+		case strings.HasPrefix(p, "["):
+			end := strings.Index(p, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in path %q", path)
+			}
+			s, err := parseBracket(p[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%w: path %q", err, path)
+			}
+			res = append(res, s)
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", p, path)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("empty path %q", path)
+	}
+	return res, nil
+}
+
+func parseBracket(s string) (segment, error) {
+	if strings.HasPrefix(s, "?(") && strings.HasSuffix(s, ")") {
+		filter := strings.TrimSuffix(strings.TrimPrefix(s, "?("), ")")
+		key, value, ok := strings.Cut(filter, "==")
+		key = strings.TrimSpace(key)
+		if !ok || !strings.HasPrefix(key, "@.") {
+			return segment{}, fmt.Errorf("unsupported filter %q, expected @.field==\"value\"", s)
+		}
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			return segment{}, fmt.Errorf("%w: filter %q", err, s)
+		}
+		return segment{filterKey: strings.TrimPrefix(key, "@."), filterValue: value}, nil
+	}
+	if index, err := strconv.Atoi(s); err == nil {
+		if index < 0 {
+			return segment{}, fmt.Errorf("negative index %d", index)
+		}
+		return segment{index: index}, nil
+	}
+	field, err := unquote(s)
+	if err != nil {
+		return segment{}, fmt.Errorf("%w: field %q", err, s)
+	}
+	return segment{field: field}, nil
+}
+
+// unquote unquotes single or double-quoted string.
+func unquote(s string) (string, error) {
+	if len(s) > 1 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+// get returns value of the object at path.
+func get(obj interface{}, path []segment) (interface{}, bool) {
+	current := obj
+	for _, s := range path {
+		switch {
+		case s.isField():
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = m[s.field]; !ok {
+				return nil, false
+			}
+		default:
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			current = nil
+			for i, item := range list {
+				if matchesItem(s, i, item) {
+					current = item
+					break
+				}
+			}
+			if current == nil {
+				return nil, false
+			}
+		}
+	}
+	return current, true
+}
+
+func matchesItem(s segment, index int, item interface{}) bool {
+	if !s.isFilter() {
+		return s.index == index
+	}
+	m, ok := item.(map[string]interface{})
+	return ok && fmt.Sprint(m[s.filterKey]) == s.filterValue
+}
+
+// set replaces the value at path in the object template expression.
+func set(expr ast.Expr, path []segment, value ast.Expr) bool {
+	s := path[0]
+	if s.isField() {
+		for _, field := range findFields(expr, s.field) {
+			if len(path) == 1 {
+				field.Value = value
+				return true
+			}
+			if set(field.Value, path[1:], value) {
+				return true
+			}
+		}
+		return false
+	}
+	list, ok := unparen(expr).(*ast.ListLit)
+	if !ok {
+		return false
+	}
+	// comprehensions appending user defined items are not counted
+	index := 0
+	for i, elt := range list.Elts {
+		item := elt
+		if c, ok := elt.(*ast.Comprehension); ok {
+			if s.isFilter() {
+				item = c.Value
+			} else {
+				continue
+			}
+		}
+		if matchesItemExpr(s, index, item) {
+			if len(path) == 1 {
+				list.Elts[i] = value
+				return true
+			}
+			return set(item, path[1:], value)
+		}
+		index++
+	}
+	return false
+}
+
+func matchesItemExpr(s segment, index int, item ast.Expr) bool {
+	if !s.isFilter() {
+		return s.index == index
+	}
+	for _, field := range findFields(item, s.filterKey) {
+		if lit, ok := field.Value.(*ast.BasicLit); ok {
+			if value, err := strconv.Unquote(lit.Value); err == nil && value == s.filterValue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findFields returns fields with given name of the struct expression, including fields of unified and embedded
+// structs and of conditional comprehensions.
+func findFields(expr ast.Node, name string) []*ast.Field {
+	var res []*ast.Field
+	switch e := expr.(type) {
+	case *ast.StructLit:
+		for _, elt := range e.Elts {
+			switch elt := elt.(type) {
+			case *ast.Field:
+				if label, _, err := ast.LabelName(elt.Label); err == nil && label == name {
+					res = append(res, elt)
+				}
+			case *ast.EmbedDecl:
+				res = append(res, findFields(elt.Expr, name)...)
+			case *ast.Comprehension:
+				res = append(res, findFields(elt.Value, name)...)
+			}
+		}
+	case *ast.BinaryExpr:
+		res = append(findFields(e.X, name), findFields(e.Y, name)...)
+	case *ast.ParenExpr:
+		res = findFields(e.X, name)
+	}
+	return res
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	if p, ok := expr.(*ast.ParenExpr); ok {
+		return unparen(p.X)
+	}
+	return expr
+}
@@ -0,0 +1,50 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePath(t *testing.T) {
+	path, err := parsePath(`{.spec.containers[?(@.name=="app")].ports[0]['containerPort']}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []segment{
+		{field: "spec"},
+		{field: "containers"},
+		{filterKey: "name", filterValue: "app"},
+		{field: "ports"},
+		{index: 0},
+		{field: "containerPort"},
+	}, path)
+
+	path, err = parsePath(`$.metadata.annotations["example.com/owner"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []segment{{field: "metadata"}, {field: "annotations"}, {field: "example.com/owner"}}, path)
+
+	for _, invalid := range []string{"", "spec", ".spec..x", ".spec[0", ".spec[?(@.name)]", ".spec[-1]"} {
+		_, err = parsePath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func Test_get(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "sidecar"},
+				map[string]interface{}{"name": "app", "command": []interface{}{"/app"}},
+			},
+		},
+	}
+	path, err := parsePath(`.spec.containers[?(@.name=="app")].command`)
+	assert.NoError(t, err)
+	value, ok := get(obj, path)
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"/app"}, value)
+
+	path, err = parsePath(`.spec.containers[0].command`)
+	assert.NoError(t, err)
+	_, ok = get(obj, path)
+	assert.False(t, ok)
+}
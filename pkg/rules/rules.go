@@ -0,0 +1,252 @@
+// Package rules lifts arbitrary object fields into #Config according to user defined parametrization rules.
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/sirupsen/logrus"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// File - parametrization rules file.
+//
+// Example:
+//
+//	rules:
+//	- selector:
+//	    kind: Deployment
+//	    name: web
+//	  path: .spec.minReadySeconds
+//	  key: web.minReadySeconds
+//	  schema: int & >=0
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule - lifts the field of selected objects into #Config.
+type Rule struct {
+	// Selector - objects the rule applies to.
+	Selector Selector `json:"selector"`
+	// Path - JSONPath of the object field, supports .field, ['field'], [index] and [?(@.field=="value")].
+	Path string `json:"path"`
+	// Key - dot separated #config key of the field, e.g. web.minReadySeconds.
+	Key string `json:"key"`
+	// Schema - CUE schema of the field, inferred from the field value if empty.
+	Schema string `json:"schema,omitempty"`
+
+	path   []segment
+	schema ast.Expr
+}
+
+// Selector - selects objects by apiVersion, kind and name, empty fields match any object.
+type Selector struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+// Load - reads and validates rules file.
+func Load(file string) ([]Rule, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read rules file", err)
+	}
+	var f File
+	if err = yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse rules file %s", err, file)
+	}
+	for i := range f.Rules {
+		if err = f.Rules[i].init(); err != nil {
+			return nil, fmt.Errorf("%w: rule %d of %s", err, i, file)
+		}
+	}
+	return f.Rules, nil
+}
+
+func (r *Rule) init() error {
+	if r.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	var err error
+	if r.path, err = parsePath(r.Path); err != nil {
+		return err
+	}
+	if r.Schema != "" {
+		if r.schema, err = parser.ParseExpr("", r.Schema); err != nil {
+			return fmt.Errorf("%w: invalid schema %q", err, r.Schema)
+		}
+	}
+	return nil
+}
+
+func (s Selector) matches(obj *unstructured.Unstructured) bool {
+	return (s.APIVersion == "" || s.APIVersion == obj.GetAPIVersion()) &&
+		(s.Kind == "" || s.Kind == obj.GetKind()) &&
+		(s.Name == "" || s.Name == obj.GetName())
+}
+
+// Apply - lifts fields of the original object selected by rules into template values and replaces them in the
+// template with #config references. obj is the object before processing, processors may modify it.
+func Apply(rules []Rule, obj *unstructured.Unstructured, tmpl timonify.Template) (timonify.Template, error) {
+	res := wrap(tmpl)
+	for _, r := range rules {
+		if !r.Selector.matches(obj) {
+			continue
+		}
+		value, ok := get(obj.Object, r.path)
+		if !ok {
+			logrus.Debugf("rule %s: %s/%s has no field %s, skipping", r.Key, obj.GetKind(), obj.GetName(), r.Path)
+			continue
+		}
+		ref, err := res.values.Add(r.schema, quoteStrings(value), strings.Split(r.Key, ".")...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to apply rule %s to %s/%s", err, r.Key, obj.GetKind(), obj.GetName())
+		}
+		res.fields = append(res.fields, field{rule: r, ref: ref})
+	}
+	if len(res.fields) == 0 && len(res.overlays) == 0 {
+		return tmpl, nil
+	}
+	return res, nil
+}
+
+// wrap returns template result to add rules to, templates are wrapped once.
+func wrap(tmpl timonify.Template) *result {
+	if res, ok := tmpl.(*result); ok {
+		return res
+	}
+	return &result{Template: tmpl, values: tmpl.Values()}
+}
+
+// quoteStrings quotes strings of the value, strings of timoni values are CUE expressions.
+func quoteStrings(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value)
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			res[k] = quoteStrings(v)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(value))
+		for i, v := range value {
+			res[i] = quoteStrings(v)
+		}
+		return res
+	}
+	return value
+}
+
+// field - object field lifted into #Config by the rule.
+type field struct {
+	rule Rule
+	ref  string
+}
+
+// result - template with fields lifted by rules and overlays unified with the object.
+type result struct {
+	timonify.Template
+	values   *timonify.Values
+	fields   []field
+	overlays []ast.Expr
+}
+
+func (r *result) Values() *timonify.Values {
+	return r.values
+}
+
+func (r *result) IsTest() bool {
+	test, ok := r.Template.(timonify.TestTemplate)
+	return ok && test.IsTest()
+}
+
+// Write - writes the template replacing lifted fields with their #config references and unifying the object
+// with overlays.
+func (r *result) Write(writer io.Writer) error {
+	var buf bytes.Buffer
+	if err := r.Template.Write(&buf); err != nil {
+		return err
+	}
+	file, err := parser.ParseFile("", buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse template", err)
+	}
+	obj, err := r.object(file)
+	if err != nil {
+		return err
+	}
+	for _, f := range r.fields {
+		if !set(obj.Value, f.rule.path, cueformat.MustParse(f.ref)) {
+			return fmt.Errorf("rule %s: field %s is not found in %s template", f.rule.Key, f.rule.Path, r.Filename())
+		}
+	}
+	if len(r.overlays) > 0 {
+		// overlays are embedded into the object struct, #config is not visible outside of it
+		objStruct := configStruct(obj.Value)
+		if objStruct == nil {
+			return fmt.Errorf("object struct with #config is not found in %s template", r.Filename())
+		}
+		for _, overlay := range r.overlays {
+			embed := &ast.EmbedDecl{Expr: overlay}
+			ast.SetRelPos(embed, token.NewSection)
+			objStruct.Elts = append(objStruct.Elts, embed)
+		}
+	}
+	formatted, err := format.Node(file)
+	if err != nil {
+		return fmt.Errorf("%w: unable to format template", err)
+	}
+	_, err = writer.Write(formatted)
+	return err
+}
+
+// configStruct returns the struct of the object definition declaring #config.
+func configStruct(expr ast.Expr) *ast.StructLit {
+	switch e := expr.(type) {
+	case *ast.StructLit:
+		for _, elt := range e.Elts {
+			if f, ok := elt.(*ast.Field); ok {
+				if label, _, err := ast.LabelName(f.Label); err == nil && label == "#config" {
+					return e
+				}
+			}
+		}
+	case *ast.BinaryExpr:
+		if res := configStruct(e.X); res != nil {
+			return res
+		}
+		return configStruct(e.Y)
+	case *ast.ParenExpr:
+		return configStruct(e.X)
+	}
+	return nil
+}
+
+// object returns the object definition of the template file.
+func (r *result) object(file *ast.File) (*ast.Field, error) {
+	name, ok := r.ObjectType().(*ast.Ident)
+	if ok {
+		for _, decl := range file.Decls {
+			if f, ok := decl.(*ast.Field); ok {
+				if label, _, err := ast.LabelName(f.Label); err == nil && label == name.Name {
+					return f, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("object definition is not found in %s template", r.Filename())
+}
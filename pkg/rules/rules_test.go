@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/processor"
+)
+
+const cmYaml = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-operator-config
+  namespace: my-operator-system
+immutable: true
+data:
+  level: info
+  upstream: api:443`
+
+const rulesYaml = `rules:
+- selector:
+    kind: ConfigMap
+    name: my-operator-config
+  path: .data.level
+  key: operator.logLevel
+  schema: '"debug" | "info"'
+- selector:
+    kind: ConfigMap
+  path: .immutable
+  key: operator.immutable
+- selector:
+    kind: Deployment
+  path: .spec.replicas
+  key: replicas
+`
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(rulesYaml), 0600))
+	rules, err := Load(file)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, Selector{Kind: "ConfigMap", Name: "my-operator-config"}, rules[0].Selector)
+	assert.Equal(t, "operator.logLevel", rules[0].Key)
+
+	for name, invalid := range map[string]string{
+		"no key":         "rules:\n- path: .spec\n",
+		"invalid path":   "rules:\n- path: spec\n  key: x\n",
+		"invalid schema": "rules:\n- path: .spec\n  key: x\n  schema: 'int &'\n",
+		"unknown field":  "rules:\n- path: .spec\n  key: x\n  jsonPath: .spec\n",
+	} {
+		assert.NoError(t, os.WriteFile(file, []byte(invalid), 0600))
+		_, err = Load(file)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApply(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(rulesYaml), 0600))
+	rules, err := Load(file)
+	assert.NoError(t, err)
+
+	obj := internal.GenerateObj(cmYaml)
+	appMeta := metadata.New(config.Config{ModuleName: "module-name"})
+	appMeta.Load(obj)
+	original := obj.DeepCopy()
+	_, tmpl, err := processor.Default().Process(appMeta, obj)
+	assert.NoError(t, err)
+
+	tmpl, err = Apply(rules, original, tmpl)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"operator": map[string]interface{}{"logLevel": `"info"`, "immutable": true},
+	}, tmpl.Values().Values)
+	cfg, err := format.Node(tmpl.Values().Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cfg), `logLevel:  "debug" | "info"`)
+	assert.Contains(t, string(cfg), `immutable: *true | bool`)
+
+	var out bytes.Buffer
+	assert.NoError(t, tmpl.Write(&out))
+	assert.Contains(t, out.String(), "immutable: #config.operator.immutable")
+	assert.Contains(t, out.String(), "level:    #config.operator.logLevel")
+	assert.Contains(t, out.String(), `upstream: "api:443"`)
+
+	notMatched, err := Apply(rules[2:], original, tmpl)
+	assert.NoError(t, err)
+	assert.Equal(t, tmpl, notMatched)
+
+	// namespace is removed from the template by the processor
+	_, tmpl, err = processor.Default().Process(appMeta, original.DeepCopy())
+	assert.NoError(t, err)
+	rule := Rule{Path: ".metadata.namespace", Key: "namespace"}
+	assert.NoError(t, rule.init())
+	tmpl, err = Apply([]Rule{rule}, original, tmpl)
+	assert.NoError(t, err)
+	assert.EqualError(t, tmpl.Write(&out), "rule namespace: field .metadata.namespace is not found in my-operator-config.cue template")
+}
@@ -5,7 +5,7 @@ import (
 	"cuelang.org/go/cue/token"
 )
 
-func defaultConfig(objects *ast.StructLit, schema ...ast.Decl) *ast.File {
+func defaultConfig(objects, tests *ast.StructLit, schema ...ast.Decl) *ast.File {
 	// Create a new file
 	file := &ast.File{}
 
@@ -97,6 +97,22 @@ func defaultConfig(objects *ast.StructLit, schema ...ast.Decl) *ast.File {
 					},
 				},
 			},
+			// selector field
+			&ast.Field{
+				Label: ast.NewIdent("selector"),
+				Value: &ast.BinaryExpr{
+					Op: token.AND,
+					X:  ast.NewSel(ast.NewIdent("timoniv1"), "#Selector"),
+					Y: &ast.StructLit{
+						Elts: []ast.Decl{
+							&ast.Field{
+								Label: ast.NewIdent("#Name"),
+								Value: ast.NewSel(ast.NewIdent("metadata"), "name"),
+							},
+						},
+					},
+				},
+			},
 			// metadata: annotations field
 			&ast.Field{
 				Label: ast.NewIdent("metadata"),
@@ -109,6 +125,22 @@ func defaultConfig(objects *ast.StructLit, schema ...ast.Decl) *ast.File {
 					},
 				},
 			},
+			// test: enabled field
+			&ast.Field{
+				Label: ast.NewIdent("test"),
+				Value: &ast.StructLit{
+					Elts: []ast.Decl{
+						&ast.Field{
+							Label: ast.NewIdent("enabled"),
+							Value: &ast.BinaryExpr{
+								Op: token.OR,
+								X:  &ast.UnaryExpr{Op: token.MUL, X: ast.NewIdent("false")},
+								Y:  ast.NewIdent("bool"),
+							},
+						},
+					},
+				},
+			},
 		),
 	}
 	configField.Value.(*ast.StructLit).Elts = append(configField.Value.(*ast.StructLit).Elts, schema...)
@@ -129,6 +161,11 @@ func defaultConfig(objects *ast.StructLit, schema ...ast.Decl) *ast.File {
 					Label: ast.NewIdent("objects"),
 					Value: objects,
 				},
+				// tests field
+				&ast.Field{
+					Label: ast.NewIdent("tests"),
+					Value: tests,
+				},
 			},
 		},
 	}
@@ -67,6 +67,11 @@ timoni: {
 	// Pass Kubernetes resources outputted by the instance
 	// to Timoni's multi-step apply.
 	%s
+
+	// Conditionally run tests after an install or upgrade.
+	if instance.config.test.enabled {
+		apply: test: [for obj in instance.tests {obj}]
+	}
 }
 `
 
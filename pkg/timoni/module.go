@@ -1,11 +1,13 @@
 package timoni
 
 import (
+	"bytes"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
 	"fmt"
 	"github.com/syndicut/timonify/pkg/cluster"
+	"github.com/syndicut/timonify/pkg/config"
 	cueformat "github.com/syndicut/timonify/pkg/cue"
 	"github.com/syndicut/timonify/pkg/timonify"
 	"os"
@@ -26,12 +28,21 @@ package main
 values: %s
 `
 
+const environmentValues = `// Code generated by timoni.
+// Values of the %s environment which differ from values.cue.
+// Apply them together with the module: timoni apply <instance> <module> -f %s
+
+values: %s
+`
+
 // NewOutput creates interface to dump processed input to filesystem in timoni module format.
-func NewOutput() timonify.Output {
-	return &output{}
+func NewOutput(config config.Config) timonify.Output {
+	return &output{config: config}
 }
 
-type output struct{}
+type output struct {
+	config config.Config
+}
 
 // Create a timoni module in the current directory:
 // moduleName/
@@ -53,22 +64,97 @@ func (o output) Create(moduleDir, moduleName string, crd bool, templates []timon
 	if err != nil {
 		return err
 	}
-	// group templates into files
+	values, err := moduleValues(templates)
+	if err != nil {
+		return err
+	}
+	return o.writeModule(filepath.Join(moduleDir, moduleName), groupFiles(templates, filenames), values)
+}
+
+// CreateEnvironments - creates a timoni module like Create from templates of the first environment, objects
+// missing in the first environment are not added to the module. Values shared by environments are written
+// to values.cue, values which differ to values-<name>.cue files:
+//
+//	moduleName/
+//	├── values.cue # Values shared by environments
+//	├── values-dev.cue # Values of the dev environment, timoni apply -f values-dev.cue
+//	└── values-prod.cue
+func (o output) CreateEnvironments(moduleDir, moduleName string, crd bool, envs []timonify.Environment) error {
+	err := initModuleDir(moduleDir, moduleName, crd)
+	if err != nil {
+		return err
+	}
+	base := envs[0]
+	values, err := moduleValues(base.Templates)
+	if err != nil {
+		return err
+	}
+	envValues := []map[string]interface{}{values.Values}
+	for _, env := range envs[1:] {
+		v, err := moduleValues(compareTemplates(base, env))
+		if err != nil {
+			return fmt.Errorf("%w: environment %s", err, env.Name)
+		}
+		// schemas of values set by other environments only
+		err = values.Merge(&timonify.Values{Config: v.Config, Values: map[string]interface{}{}})
+		if err != nil {
+			return err
+		}
+		envValues = append(envValues, v.Values)
+	}
+	shared, diffs := timonify.SplitEnvironments(envValues)
+	values.Values = shared
+	cDir := filepath.Join(moduleDir, moduleName)
+	err = o.writeModule(cDir, groupFiles(base.Templates, base.Filenames), values)
+	if err != nil {
+		return err
+	}
+	for i, env := range envs {
+		err = overwriteEnvironmentValuesFile(cDir, env.Name, diffs[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupFiles groups templates into files.
+func groupFiles(templates []timonify.Template, filenames []string) map[string][]timonify.Template {
 	files := map[string][]timonify.Template{}
+	for i, template := range templates {
+		files[filenames[i]] = append(files[filenames[i]], template)
+	}
+	return files
+}
+
+// moduleValues merges values of templates.
+func moduleValues(templates []timonify.Template) (*timonify.Values, error) {
 	values := timonify.NewValues()
 	if _, err := values.Add(ast.NewIdent("string"), strconv.Quote(cluster.DefaultDomain), cluster.DomainKey); err != nil {
-		return fmt.Errorf("%w: unable to set domain value", err)
+		return nil, fmt.Errorf("%w: unable to set domain value", err)
 	}
-	for i, template := range templates {
-		file := files[filenames[i]]
-		file = append(file, template)
-		files[filenames[i]] = file
-		err = values.Merge(template.Values())
+	for _, template := range templates {
+		if err := values.Merge(template.Values()); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (o output) writeModule(cDir string, files map[string][]timonify.Template, values *timonify.Values) error {
+	var err error
+	if o.config.HoistSharedValues {
+		err = values.HoistShared()
+		if err != nil {
+			return err
+		}
+	}
+	if o.config.DefaultsInConfig {
+		err = values.EmbedDefaults()
 		if err != nil {
 			return err
 		}
 	}
-	cDir := filepath.Join(moduleDir, moduleName)
 	for filename, tpls := range files {
 		err = overwriteTemplateFile(filename, cDir, tpls)
 		if err != nil {
@@ -86,6 +172,49 @@ func (o output) Create(moduleDir, moduleName string, crd bool, templates []timon
 	return nil
 }
 
+// compareTemplates returns templates of the environment objects which are in the base environment, the module
+// is built from the base environment templates, so values of other objects are left out. Warns about objects
+// missing in either environment or differing from the base environment.
+func compareTemplates(base, env timonify.Environment) []timonify.Template {
+	baseTemplates := map[string]string{}
+	for _, t := range base.Templates {
+		baseTemplates[objectName(t)] = render(t)
+	}
+	res := make([]timonify.Template, 0, len(env.Templates))
+	for _, t := range env.Templates {
+		name := objectName(t)
+		rendered, ok := baseTemplates[name]
+		switch {
+		case !ok:
+			logrus.Warnf("%s of environment %s is missing in environment %s, it is not added to the module", name, env.Name, base.Name)
+			continue
+		case rendered != render(t):
+			logrus.Warnf("%s of environment %s differs from environment %s in fields which are not in #config, template of %s is used", name, env.Name, base.Name, base.Name)
+		}
+		res = append(res, t)
+		delete(baseTemplates, name)
+	}
+	for name := range baseTemplates {
+		logrus.Warnf("%s of environment %s is missing in environment %s", name, base.Name, env.Name)
+	}
+	return res
+}
+
+func objectName(t timonify.Template) string {
+	if ident, ok := t.ObjectType().(*ast.Ident); ok {
+		return ident.Name
+	}
+	return t.Filename()
+}
+
+func render(t timonify.Template) string {
+	var buf bytes.Buffer
+	if err := t.Write(&buf); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
 func overwriteTemplateFile(filename, moduleDir string, templates []timonify.Template) error {
 	subdir := "templates"
 	file := filepath.Join(moduleDir, subdir, filename)
@@ -126,11 +255,31 @@ func overwriteValuesFile(moduleDir string, values *timonify.Values) error {
 	return nil
 }
 
+func overwriteEnvironmentValuesFile(moduleDir, name string, values map[string]interface{}) error {
+	res, err := cueformat.Marshal(values, 0, true)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal values of environment %s", err, name)
+	}
+	filename := fmt.Sprintf("values-%s.cue", name)
+	file := filepath.Join(moduleDir, filename)
+	err = os.WriteFile(file, []byte(fmt.Sprintf(environmentValues, name, filename, res)), 0600)
+	if err != nil {
+		return fmt.Errorf("%w: unable to write %s", err, file)
+	}
+	logrus.WithField("file", file).Info("overwritten")
+	return nil
+}
+
 func overwriteConfigFile(moduleDir string, values *timonify.Values, files map[string][]timonify.Template) error {
 	objectsNode := ast.NewStruct()
+	testsNode := ast.NewStruct()
 	for _, templates := range files {
 		for _, t := range templates {
-			objectsNode.Elts = append(objectsNode.Elts,
+			node := objectsNode
+			if test, ok := t.(timonify.TestTemplate); ok && test.IsTest() {
+				node = testsNode
+			}
+			node.Elts = append(node.Elts,
 				&ast.Field{
 					Label: t.ObjectLabel(),
 					Value: &ast.BinaryExpr{
@@ -151,7 +300,7 @@ func overwriteConfigFile(moduleDir string, values *timonify.Values, files map[st
 	}
 
 	file := filepath.Join(moduleDir, "templates", "config.cue")
-	b, err := format.Node(defaultConfig(objectsNode, values.Config.Elts...))
+	b, err := format.Node(defaultConfig(objectsNode, testsNode, values.Config.Elts...))
 	if err != nil {
 		return fmt.Errorf("%w: unable to format config.cue", err)
 	}
@@ -0,0 +1,100 @@
+package timoni
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndicut/timonify/internal"
+	"github.com/syndicut/timonify/pkg/config"
+	"github.com/syndicut/timonify/pkg/metadata"
+	"github.com/syndicut/timonify/pkg/processor/deployment"
+	"github.com/syndicut/timonify/pkg/processor/replicaset"
+	"github.com/syndicut/timonify/pkg/timonify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const envWorkload = `apiVersion: apps/v1
+kind: %[1]s
+metadata:
+  name: %[2]s
+spec:
+  replicas: %[3]d
+  selector:
+    matchLabels:
+      app: %[2]s
+  template:
+    metadata:
+      labels:
+        app: %[2]s
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.14.2
+`
+
+// environment returns environment with web Deployment and, if cacheReplicas is set, cache ReplicaSet.
+func environment(t *testing.T, name string, webReplicas, cacheReplicas int) timonify.Environment {
+	objects := []*unstructured.Unstructured{internal.GenerateObj(fmt.Sprintf(envWorkload, "Deployment", "web", webReplicas))}
+	if cacheReplicas != 0 {
+		objects = append(objects, internal.GenerateObj(fmt.Sprintf(envWorkload, "ReplicaSet", "cache", cacheReplicas)))
+	}
+	appMeta := metadata.New(config.Config{ModuleName: "module"})
+	for _, obj := range objects {
+		appMeta.Load(obj)
+	}
+	env := timonify.Environment{Name: name}
+	for _, obj := range objects {
+		processor := deployment.New()
+		if obj.GetKind() == "ReplicaSet" {
+			processor = replicaset.New()
+		}
+		_, tmpl, err := processor.Process(appMeta, obj)
+		assert.NoError(t, err)
+		env.Templates = append(env.Templates, tmpl)
+		env.Filenames = append(env.Filenames, tmpl.Filename())
+	}
+	return env
+}
+
+func TestOutput_CreateEnvironments(t *testing.T) {
+	moduleDir := t.TempDir()
+	cDir := filepath.Join(moduleDir, "module")
+	// existing module skeleton is kept, so timoni is not called to vendor schemas
+	assert.NoError(t, os.MkdirAll(filepath.Join(cDir, "templates"), 0750))
+	assert.NoError(t, os.WriteFile(filepath.Join(cDir, "timoni.cue"), nil, 0600))
+
+	envs := []timonify.Environment{
+		environment(t, "dev", 1, 0),
+		// cache is missing in dev, it is not added to the module
+		environment(t, "prod", 3, 5),
+	}
+	err := NewOutput(config.Config{}).CreateEnvironments(moduleDir, "module", false, envs)
+	assert.NoError(t, err)
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(cDir, name))
+		assert.NoError(t, err)
+		return string(b)
+	}
+	values := read("values.cue")
+	assert.Contains(t, values, `repository: "nginx"`)
+	assert.NotContains(t, values, "replicas")
+
+	dev := read("values-dev.cue")
+	assert.Contains(t, dev, "// Apply them together with the module: timoni apply <instance> <module> -f values-dev.cue")
+	assert.Contains(t, dev, "replicas: 1")
+
+	prod := read("values-prod.cue")
+	assert.Contains(t, prod, "replicas: 3")
+	assert.NotContains(t, prod, "cache")
+	assert.NotContains(t, prod, "replicas: 5")
+
+	cfg := read(filepath.Join("templates", "config.cue"))
+	assert.Contains(t, cfg, "web: {")
+	assert.NotContains(t, cfg, "cache")
+	_, err = os.Stat(filepath.Join(cDir, "templates", "deployment.cue"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,163 @@
+package timonify
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// configRef - #config value set by the object template at the manifest path.
+type configRef struct {
+	source []string
+	config []string
+}
+
+// AddComments - adds comments of the object manifest fields as doc comments of the #config fields the manifest
+// fields are set from. source is CUE source of the object template, see Template.Write. E.g. the comment of
+// spec.replicas is added to #config.app.replicas if the template sets replicas: #config.app.replicas in spec.
+// Comments of nested manifest fields are added to the matching nested #config fields, comments of fields which
+// are not set from #config are skipped.
+func (v *Values) AddComments(source []byte, comments []Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	file, err := parser.ParseFile("", source)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse template", err)
+	}
+	var refs []configRef
+	for _, decl := range file.Decls {
+		if field, ok := decl.(*ast.Field); ok {
+			collectConfigRefs(field.Value, nil, &refs)
+		}
+	}
+	for _, comment := range comments {
+		if field := v.commentField(refs, comment.Path); field != nil {
+			addDocComment(field, comment.Text)
+		}
+	}
+	return nil
+}
+
+// commentField returns #config field set from the manifest field at path or from one of its parents,
+// the closest one wins.
+func (v *Values) commentField(refs []configRef, path []string) *ast.Field {
+	var res *ast.Field
+	matchLen := -1
+	for _, ref := range refs {
+		if len(ref.source) <= matchLen || len(ref.source) > len(path) || !slices.Equal(ref.source, path[:len(ref.source)]) {
+			continue
+		}
+		field := configField(v.Config, append(append([]string{}, ref.config...), path[len(ref.source):]...))
+		if field == nil && len(ref.source) == len(path) && len(ref.config) > 1 {
+			// computed fields, e.g. image.reference, are documented at their parent
+			field = configField(v.Config, ref.config[:len(ref.config)-1])
+		}
+		if field != nil {
+			res, matchLen = field, len(ref.source)
+		}
+	}
+	return res
+}
+
+// collectConfigRefs collects #config references of the template fields with manifest paths of the fields.
+// List items are keyed by their names or indexes, same as manifest comments.
+func collectConfigRefs(expr ast.Expr, path []string, refs *[]configRef) {
+	switch e := expr.(type) {
+	case *ast.StructLit:
+		for _, elt := range e.Elts {
+			switch elt := elt.(type) {
+			case *ast.Field:
+				collectConfigRefs(elt.Value, append(append([]string{}, path...), labelName(elt.Label)), refs)
+			case *ast.EmbedDecl:
+				collectConfigRefs(elt.Expr, path, refs)
+			case *ast.Comprehension:
+				collectConfigRefs(elt.Value, path, refs)
+			}
+		}
+	case *ast.ListLit:
+		for i, elt := range e.Elts {
+			collectConfigRefs(elt, append(append([]string{}, path...), listItemKey(elt, i)), refs)
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.AND {
+			collectConfigRefs(e.X, path, refs)
+			collectConfigRefs(e.Y, path, refs)
+		}
+	case *ast.ParenExpr:
+		collectConfigRefs(e.X, path, refs)
+	case *ast.SelectorExpr:
+		if config, ok := configPath(e); ok {
+			*refs = append(*refs, configRef{source: path, config: config})
+		}
+	}
+}
+
+// configPath returns path of the #config.<path> selector.
+func configPath(sel *ast.SelectorExpr) ([]string, bool) {
+	var path []string
+	var expr ast.Expr = sel
+	for {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			path = append([]string{labelName(e.Sel)}, path...)
+			expr = e.X
+		case *ast.Ident:
+			return path, e.Name == "#config"
+		default:
+			return nil, false
+		}
+	}
+}
+
+// listItemKey returns name of the list item, e.g. container name, or its index.
+func listItemKey(item ast.Expr, index int) string {
+	if s, ok := item.(*ast.StructLit); ok {
+		if field := findField(s, "name"); field != nil {
+			if lit, ok := field.Value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if name, err := strconv.Unquote(lit.Value); err == nil {
+					return name
+				}
+			}
+		}
+	}
+	return strconv.Itoa(index)
+}
+
+// configField returns #config field at path, manifest names are matched by their normalized keys as well.
+func configField(config *ast.StructLit, path []string) *ast.Field {
+	var res *ast.Field
+	structs := []*ast.StructLit{config}
+	for _, name := range path {
+		res = nil
+		for _, s := range structs {
+			fields := findFields(s, name)
+			if len(fields) == 0 {
+				fields = findFields(s, LabelName(NameKey(name)))
+			}
+			if len(fields) != 0 {
+				res = fields[0]
+				break
+			}
+		}
+		if res == nil {
+			return nil
+		}
+		structs = structConjuncts(res.Value)
+	}
+	return res
+}
+
+func addDocComment(field *ast.Field, text string) {
+	lines := strings.Split(text, "\n")
+	comments := make([]*ast.Comment, 0, len(lines))
+	for _, line := range lines {
+		comments = append(comments, &ast.Comment{Text: "// " + line})
+	}
+	ast.AddComment(field, &ast.CommentGroup{Doc: true, List: comments})
+}
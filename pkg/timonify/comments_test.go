@@ -0,0 +1,81 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+)
+
+const commentsTemplate = `package templates
+
+#Deployment: appsv1.#Deployment & {
+	#config: #Config
+	metadata: name: #config.metadata.name + "-web"
+	spec: appsv1.#DeploymentSpec & {
+		replicas: #config.web.replicas
+		template: spec: corev1.#PodSpec & {
+			containers: [{
+				name:            "app"
+				image:           #config.web.app.image.reference
+				securityContext: #config.web.app.containerSecurityContext
+				env: [{
+					name:  "LOG_LEVEL"
+					value: #config.web.app.env.logLevel
+				}]
+				args: ["--v"]
+			}, for v in #config.web.extraContainers {v}]
+		}
+	}
+}
+`
+
+func TestValues_AddComments(t *testing.T) {
+	values := NewValues()
+	_, err := values.Add(ast.NewIdent("int"), int64(2), "web", "replicas")
+	assert.NoError(t, err)
+	_, err = values.Add(ast.NewIdent("string"), "info", "web", "app", "env", "LOG_LEVEL")
+	assert.NoError(t, err)
+	_, err = values.Add(ast.NewIdent("string"), "web", "web", "app", "image", "repository")
+	assert.NoError(t, err)
+	_, err = values.Add(nil, map[string]interface{}{"runAsUser": int64(1000)}, "web", "app", "containerSecurityContext")
+	assert.NoError(t, err)
+
+	err = values.AddComments([]byte(commentsTemplate), []Comment{
+		{Path: []string{"spec", "replicas"}, Text: "bump this when the DB schema changes"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "name"}, Text: "main container"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "image"}, Text: "pinned"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "securityContext", "runAsUser"}, Text: "nobody"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "env", "LOG_LEVEL", "value"}, Text: "debug in staging\nINFO otherwise"},
+		{Path: []string{"spec", "template", "spec", "containers", "app", "args", "0"}, Text: "not a config value"},
+	})
+	assert.NoError(t, err)
+
+	cfg, err := format.Node(values.Config)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+	web: {
+		// bump this when the DB schema changes
+		replicas: int
+		app: {
+			env: {
+				// debug in staging
+				// INFO otherwise
+				logLevel: string
+			}
+			// pinned
+			image: {
+				repository: string
+			}
+			containerSecurityContext: {
+				// nobody
+				runAsUser: *1000 | int
+				...
+			}
+		}
+	}
+}`, string(cfg))
+
+	assert.Error(t, values.AddComments([]byte("{"), []Comment{{Path: []string{"spec"}, Text: "broken"}}))
+}
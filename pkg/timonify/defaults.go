@@ -0,0 +1,218 @@
+package timonify
+
+import (
+	"fmt"
+	"sort"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+)
+
+// EmbedDefaults - moves values into #Config as defaults, so the module can be built without values.cue.
+// Scalars and lists become *value | schema, maps under non-struct schemas are unified with a struct of defaults.
+// Existing schema defaults are replaced and fields with defaults are no longer required or optional.
+// Values are cleared afterwards, values.cue keeps user overrides only.
+func (v *Values) EmbedDefaults() error {
+	if err := embedDefaults(v.Config, v.Values); err != nil {
+		return fmt.Errorf("%w: unable to embed defaults into config", err)
+	}
+	v.Values = map[string]interface{}{}
+	return nil
+}
+
+func embedDefaults(schema *ast.StructLit, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields := findFields(schema, k)
+		if len(fields) == 0 {
+			inferred, err := inferSchema(values[k])
+			if err != nil {
+				return err
+			}
+			field := &ast.Field{Label: newLabel(k), Value: inferred}
+			schema.Elts = append(schema.Elts, field)
+			for _, elt := range schema.Elts {
+				ast.SetRelPos(elt, token.Newline)
+			}
+			continue
+		}
+		for _, field := range fields {
+			if err := embedDefault(field, values[k]); err != nil {
+				return fmt.Errorf("%w: %s", err, k)
+			}
+		}
+	}
+	return nil
+}
+
+func embedDefault(field *ast.Field, value interface{}) error {
+	if _, ok := field.Value.(*ast.BottomLit); ok {
+		// field is forbidden in this branch of the schema
+		return nil
+	}
+	field.Constraint = token.ILLEGAL
+	field.Optional = token.NoPos
+	if m, ok := value.(map[string]interface{}); ok {
+		if s, ok := field.Value.(*ast.StructLit); ok {
+			return embedDefaults(s, m)
+		}
+		// defaults of struct conjuncts, e.g. timoniv1.#ResourceRequirements & {requests: {cpu: *"10m" | ...}},
+		// are replaced, other values are unified with the schema
+		rest := map[string]interface{}{}
+		for k, val := range m {
+			var fields []*ast.Field
+			for _, s := range structConjuncts(field.Value) {
+				fields = append(fields, findFields(s, k)...)
+			}
+			if len(fields) == 0 {
+				rest[k] = val
+				continue
+			}
+			for _, f := range fields {
+				if err := embedDefault(f, val); err != nil {
+					return fmt.Errorf("%w: %s", err, k)
+				}
+			}
+		}
+		if len(rest) == 0 {
+			return nil
+		}
+		inferred, err := inferSchema(rest)
+		if err != nil {
+			return err
+		}
+		field.Value = ast.NewBinExpr(token.AND, parensIfDisjunction(field.Value), inferred)
+		return nil
+	}
+	src, ok := value.(string)
+	if !ok {
+		var err error
+		src, err = cueformat.Marshal(value, 0, true)
+		if err != nil {
+			return err
+		}
+	}
+	lit, err := parser.ParseExpr("", src)
+	if err != nil {
+		return err
+	}
+	res := ast.Expr(&ast.UnaryExpr{Op: token.MUL, X: lit})
+	typed := false
+	for _, disjunct := range disjuncts(field.Value) {
+		if _, ok := disjunct.(*ast.BasicLit); !ok {
+			typed = true
+		}
+	}
+	oldDefaults := defaults(field.Value)
+	for _, disjunct := range disjuncts(field.Value) {
+		if sameExpr(disjunct, lit) {
+			continue
+		}
+		// old literal defaults allowed by a type of the schema are dropped, e.g. 1 of *1 | int & >0,
+		// enum values, e.g. "RollingUpdate" of *"RollingUpdate" | "Recreate", are kept
+		if _, ok := disjunct.(*ast.BasicLit); ok && typed && containsExpr(oldDefaults, disjunct) {
+			continue
+		}
+		res = ast.NewBinExpr(token.OR, res, disjunct)
+	}
+	field.Value = res
+	return nil
+}
+
+// findFields returns fields with given name of the struct including fields of its conditional comprehensions.
+func findFields(schema *ast.StructLit, name string) []*ast.Field {
+	var res []*ast.Field
+	for _, elt := range schema.Elts {
+		switch elt := elt.(type) {
+		case *ast.Field:
+			if labelName(elt.Label) == name {
+				res = append(res, elt)
+			}
+		case *ast.Comprehension:
+			if s, ok := elt.Value.(*ast.StructLit); ok {
+				res = append(res, findFields(s, name)...)
+			}
+		}
+	}
+	return res
+}
+
+// structConjuncts returns struct literals unified by the schema.
+func structConjuncts(expr ast.Expr) []*ast.StructLit {
+	switch e := expr.(type) {
+	case *ast.StructLit:
+		return []*ast.StructLit{e}
+	case *ast.ParenExpr:
+		return structConjuncts(e.X)
+	case *ast.BinaryExpr:
+		if e.Op == token.AND {
+			return append(structConjuncts(e.X), structConjuncts(e.Y)...)
+		}
+	}
+	return nil
+}
+
+// defaults returns default disjuncts of the schema without default markers.
+func defaults(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.OR {
+			return append(defaults(e.X), defaults(e.Y)...)
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.MUL {
+			return []ast.Expr{e.X}
+		}
+	}
+	return nil
+}
+
+func containsExpr(exprs []ast.Expr, expr ast.Expr) bool {
+	for _, e := range exprs {
+		if sameExpr(e, expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// disjuncts returns top level disjuncts of the schema without default markers.
+func disjuncts(expr ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.OR {
+			return append(disjuncts(e.X), disjuncts(e.Y)...)
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.MUL {
+			return []ast.Expr{e.X}
+		}
+	}
+	return []ast.Expr{expr}
+}
+
+func sameExpr(x, y ast.Expr) bool {
+	xSrc, err := format.Node(x)
+	if err != nil {
+		return false
+	}
+	ySrc, err := format.Node(y)
+	if err != nil {
+		return false
+	}
+	return string(xSrc) == string(ySrc)
+}
+
+func parensIfDisjunction(expr ast.Expr) ast.Expr {
+	if e, ok := expr.(*ast.BinaryExpr); ok && e.Op == token.OR {
+		return &ast.ParenExpr{X: e}
+	}
+	return expr
+}
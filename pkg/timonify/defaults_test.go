@@ -0,0 +1,86 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValues_EmbedDefaults(t *testing.T) {
+	v := NewValues()
+	mustParse := func(src string) ast.Expr {
+		expr, err := parser.ParseExpr("", src)
+		assert.NoError(t, err)
+		return expr
+	}
+	_, err := v.Add(mustParse("*1 | int & >0"), int64(3), "app", "replicas")
+	assert.NoError(t, err)
+	_, err = v.Add(mustParse("*true | bool"), true, "app", "enabled")
+	assert.NoError(t, err)
+	_, err = v.Add(mustParse("[...string]"), []interface{}{`"--verbose"`}, "app", "args")
+	assert.NoError(t, err)
+	err = v.AddConfig(ast.NewSel(ast.NewIdent("timoniv1"), "#Image"), true, "app", "image")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, `"nginx"`, "app", "image", "repository")
+	assert.NoError(t, err)
+	_, err = v.Add(mustParse(`{
+	type: *"RollingUpdate" | "Recreate"
+	if type == "RollingUpdate" {
+		rollingUpdate?: maxSurge?: int | string
+	}
+	if type == "Recreate" {
+		rollingUpdate?: _|_
+	}
+}`), map[string]interface{}{
+		"type":          `"RollingUpdate"`,
+		"rollingUpdate": map[string]interface{}{"maxSurge": `"25%"`},
+	}, "app", "strategy")
+	assert.NoError(t, err)
+	err = v.AddConfig(mustParse(`timoniv1.#ResourceRequirements & {requests: cpu: *"10m" | string}`), false, "app", "resources")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": `"100m"`},
+		"limits":   map[string]interface{}{"cpu": `"1"`},
+	}, "app", "resources")
+	assert.NoError(t, err)
+	_, err = v.Add(mustParse(`*"RollingUpdate" | "Recreate"`), `"Recreate"`, "app", "updateType")
+	assert.NoError(t, err)
+
+	err = v.EmbedDefaults()
+	assert.NoError(t, err)
+	assert.Empty(t, v.Values)
+
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+	app: {
+		replicas: *3 | int & >0
+		enabled:  *true | bool
+		args: *["--verbose"] | [...string]
+		image: timoniv1.#Image & {
+			repository: *"nginx" | string
+			...
+		}
+		strategy: {
+			type: *"RollingUpdate" | "Recreate"
+			if type == "RollingUpdate" {
+				rollingUpdate: maxSurge: *"25%" | int | string
+			}
+			if type == "Recreate" {
+				rollingUpdate?: _|_
+			}
+		}
+		resources: timoniv1.#ResourceRequirements & {requests: cpu: *"100m" | string} & {
+			limits: {
+				cpu: *"1" | string
+				...
+			}
+			...
+		}
+		updateType: *"Recreate" | "RollingUpdate"
+	}
+}`, string(b))
+}
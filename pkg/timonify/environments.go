@@ -0,0 +1,67 @@
+package timonify
+
+import (
+	"reflect"
+)
+
+// SplitEnvironments - splits values of environments into values equal in every environment and values of every
+// environment which differ, e.g. replicas: 1 in dev and replicas: 3 in prod. Maps are compared by their fields,
+// other values, including lists, as a whole. Values missing in some environments differ as well.
+func SplitEnvironments(envs []map[string]interface{}) (map[string]interface{}, []map[string]interface{}) {
+	shared := map[string]interface{}{}
+	diffs := make([]map[string]interface{}, len(envs))
+	for i := range diffs {
+		diffs[i] = map[string]interface{}{}
+	}
+	keys := map[string]bool{}
+	for _, env := range envs {
+		for k := range env {
+			keys[k] = true
+		}
+	}
+	for _, k := range sortedKeys(keys) {
+		values := make([]interface{}, len(envs))
+		maps := make([]map[string]interface{}, len(envs))
+		inAll, allMaps := true, true
+		for i, env := range envs {
+			value, ok := env[k]
+			inAll = inAll && ok
+			values[i] = value
+			maps[i], ok = value.(map[string]interface{})
+			allMaps = allMaps && ok
+		}
+		switch {
+		case inAll && allMaps:
+			sharedMap, diffMaps := SplitEnvironments(maps)
+			differs := false
+			for i, diff := range diffMaps {
+				if len(diff) != 0 {
+					diffs[i][k] = diff
+					differs = true
+				}
+			}
+			// empty maps are kept as they are
+			if len(sharedMap) != 0 || !differs {
+				shared[k] = sharedMap
+			}
+		case inAll && allEqual(values):
+			shared[k] = values[0]
+		default:
+			for i, env := range envs {
+				if value, ok := env[k]; ok {
+					diffs[i][k] = value
+				}
+			}
+		}
+	}
+	return shared, diffs
+}
+
+func allEqual(values []interface{}) bool {
+	for _, v := range values[1:] {
+		if !reflect.DeepEqual(values[0], v) {
+			return false
+		}
+	}
+	return true
+}
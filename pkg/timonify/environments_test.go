@@ -0,0 +1,74 @@
+package timonify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitEnvironments(t *testing.T) {
+	dev := map[string]interface{}{
+		"web": map[string]interface{}{
+			"replicas": int64(1),
+			"app": map[string]interface{}{
+				"image": map[string]interface{}{"repository": `"ghcr.io/acme/web"`, "tag": `"1.0-dev"`},
+				"env":   map[string]interface{}{"logLevel": `"debug"`, "region": `"eu"`},
+			},
+			"extraVolumes":       []interface{}{},
+			"podSecurityContext": map[string]interface{}{},
+			"args":               []interface{}{`"--dev"`},
+		},
+		"debug": map[string]interface{}{"enabled": true},
+	}
+	prod := map[string]interface{}{
+		"web": map[string]interface{}{
+			"replicas": int64(3),
+			"app": map[string]interface{}{
+				"image": map[string]interface{}{"repository": `"ghcr.io/acme/web"`, "tag": `"1.0"`},
+				"env":   map[string]interface{}{"logLevel": `"info"`, "region": `"eu"`},
+			},
+			"extraVolumes":       []interface{}{},
+			"podSecurityContext": map[string]interface{}{},
+			"args":               []interface{}{`"--dev"`, `"--prod"`},
+		},
+	}
+
+	shared, diffs := SplitEnvironments([]map[string]interface{}{dev, prod})
+	assert.Equal(t, map[string]interface{}{
+		"web": map[string]interface{}{
+			"app": map[string]interface{}{
+				"image": map[string]interface{}{"repository": `"ghcr.io/acme/web"`},
+				"env":   map[string]interface{}{"region": `"eu"`},
+			},
+			"extraVolumes":       []interface{}{},
+			"podSecurityContext": map[string]interface{}{},
+		},
+	}, shared)
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"web": map[string]interface{}{
+				"replicas": int64(1),
+				"app": map[string]interface{}{
+					"image": map[string]interface{}{"tag": `"1.0-dev"`},
+					"env":   map[string]interface{}{"logLevel": `"debug"`},
+				},
+				"args": []interface{}{`"--dev"`},
+			},
+			"debug": map[string]interface{}{"enabled": true},
+		},
+		{
+			"web": map[string]interface{}{
+				"replicas": int64(3),
+				"app": map[string]interface{}{
+					"image": map[string]interface{}{"tag": `"1.0"`},
+					"env":   map[string]interface{}{"logLevel": `"info"`},
+				},
+				"args": []interface{}{`"--dev"`, `"--prod"`},
+			},
+		},
+	}, diffs)
+
+	shared, diffs = SplitEnvironments([]map[string]interface{}{prod})
+	assert.Equal(t, prod, shared)
+	assert.Equal(t, []map[string]interface{}{{}}, diffs)
+}
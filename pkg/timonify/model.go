@@ -7,6 +7,7 @@ import (
 	"github.com/syndicut/timonify/pkg/config"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // Processor - converts k8s object to helm template.
@@ -31,9 +32,26 @@ type Template interface {
 	ObjectLabel() ast.Label
 }
 
+// TestTemplate - optional Template extension for objects which are Timoni module tests.
+// Tests are rendered into #Instance.tests and applied only when #config.test.enabled is set.
+type TestTemplate interface {
+	// IsTest - returns true if the object is a module test.
+	IsTest() bool
+}
+
 // Output - converts Template into helm module on disk.
 type Output interface {
 	Create(moduleName, moduleDir string, Crd bool, templates []Template, filenames []string) error
+	// CreateEnvironments - creates module from templates of the first environment, values shared by environments
+	// go to values.cue, values which differ go to values-<name>.cue of every environment.
+	CreateEnvironments(moduleDir, moduleName string, crd bool, envs []Environment) error
+}
+
+// Environment - templates of a module environment, e.g. dev or prod overlay of the app.
+type Environment struct {
+	Name      string
+	Templates []Template
+	Filenames []string
 }
 
 // AppMetadata handle common information about K8s objects in the module.
@@ -52,6 +70,42 @@ type AppMetadata interface {
 	// TrimName trims common prefix from object name if exists.
 	// We trim common prefix because helm already using release for this purpose.
 	TrimName(objName string) string
+	// ValuesName returns name of the object values in #config according to the configured values naming.
+	// Example: Deployment "my-app-worker" -> "worker" or "deploymentWorker".
+	ValuesName(obj *unstructured.Unstructured) string
 
 	Config() config.Config
+
+	// AddContainerPorts registers parametrized container ports of module pods with given labels.
+	AddContainerPorts(podLabels map[string]string, ports []ContainerPort)
+	// ContainerPort returns parametrized container port of module pods matching given service selector.
+	// Port is looked up by name or by number. Ports are registered during processing, so lookups
+	// should be done on template Write.
+	ContainerPort(selector map[string]string, port intstr.IntOrString) (ContainerPort, bool)
+	// SelectsModulePods returns true if given selector matches labels of any module pod.
+	// Pods are registered during processing, so lookups should be done on template Write.
+	SelectsModulePods(selector map[string]string) bool
+	// AddObjectType registers CUE type of processed module object with given kind and original name.
+	AddObjectType(kind, name string, objType ast.Expr)
+	// ObjectType returns CUE type of module object with given kind and original name. Object types are
+	// registered during processing, so lookups should be done on template Write.
+	ObjectType(kind, name string) (ast.Expr, bool)
+}
+
+// ContainerPort - container port exposed in #Config.
+type ContainerPort struct {
+	// Name - port name, optional.
+	Name string
+	// Port - original port number.
+	Port int32
+	// Reference - #config reference to the port number.
+	Reference string
+}
+
+// Comment - comment of the input manifest field.
+type Comment struct {
+	// Path - field path in the manifest, list items are keyed by their name or index.
+	Path []string
+	// Text - comment text without comment markers.
+	Text string
 }
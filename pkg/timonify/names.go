@@ -0,0 +1,144 @@
+package timonify
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// NameKeys - maps sibling kubernetes names, e.g. container or env var names of the same container, to #config keys.
+// Names are normalized to lower camel case unless the result is not a valid CUE identifier or several names
+// normalize to the same key. Such names are kept as is and quoted, so keys stay unique and map back to the
+// original names.
+type NameKeys struct {
+	keys      map[string]string
+	originals map[string]string
+	clashes   map[string][]string
+}
+
+// NewNameKeys - returns keys of given sibling names.
+func NewNameKeys(names ...string) NameKeys {
+	k := NameKeys{
+		keys:      make(map[string]string, len(names)),
+		originals: make(map[string]string, len(names)),
+		clashes:   map[string][]string{},
+	}
+	byKey := map[string][]string{}
+	for _, name := range names {
+		key := NameKey(name)
+		if !contains(byKey[key], name) {
+			byKey[key] = append(byKey[key], name)
+		}
+	}
+	for key, originals := range byKey {
+		if len(originals) > 1 {
+			sort.Strings(originals)
+			k.clashes[key] = originals
+		}
+		for _, name := range originals {
+			if len(originals) > 1 {
+				key = strconv.Quote(name)
+			}
+			k.keys[name] = key
+			k.originals[LabelName(key)] = name
+		}
+	}
+	return k
+}
+
+// Key - returns #config key of the name as CUE selector source, e.g. myVar or "MY_VAR".
+func (k NameKeys) Key(name string) string {
+	if key, ok := k.keys[name]; ok {
+		return key
+	}
+	return NameKey(name)
+}
+
+// Original - returns kubernetes name the key was made of.
+func (k NameKeys) Original(key string) string {
+	if name, ok := k.originals[LabelName(key)]; ok {
+		return name
+	}
+	return LabelName(key)
+}
+
+// Clashes - returns names normalizing to the same key, by that key.
+func (k NameKeys) Clashes() map[string][]string {
+	return k.clashes
+}
+
+// NameKey - normalizes kubernetes name to lower camel case #config key. Names which can not be normalized to
+// a valid CUE identifier are quoted as is.
+func NameKey(name string) string {
+	if isQuoted(name) {
+		return name
+	}
+	key := strcase.ToLowerCamel(name)
+	if name == strings.ToUpper(name) {
+		key = strcase.ToLowerCamel(strings.ToLower(name))
+	}
+	if LabelSource(key) != key {
+		return strconv.Quote(name)
+	}
+	return key
+}
+
+// LabelName - returns field name of the #config key, quoted keys are unquoted.
+func LabelName(key string) string {
+	if !isQuoted(key) {
+		return key
+	}
+	if name, err := strconv.Unquote(key); err == nil {
+		return name
+	}
+	return key
+}
+
+// ContainerKeys - returns keys of containers and init containers of the pod spec, they share
+// #config.<objName> scope.
+func ContainerKeys(specMap map[string]interface{}) NameKeys {
+	var names []string
+	for _, containerType := range []string{"containers", "initContainers"} {
+		containers, _ := specMap[containerType].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := container["name"].(string); ok {
+				names = append(names, LabelName(name))
+			}
+		}
+	}
+	return NewNameKeys(names...)
+}
+
+// ContainerKey - returns #config key of the pod spec container, container names may be quoted for CUE.
+func (k NameKeys) ContainerKey(container map[string]interface{}) string {
+	name, _ := container["name"].(string)
+	return k.Key(LabelName(name))
+}
+
+func isQuoted(s string) bool {
+	return len(s) > 1 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// labelPath - returns #config path of given field names.
+func labelPath(name []string) string {
+	res := make([]string, len(name))
+	for i, n := range name {
+		res[i] = LabelSource(n)
+	}
+	return strings.Join(res, ".")
+}
@@ -0,0 +1,56 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameKeys(t *testing.T) {
+	keys := NewNameKeys("MY_VAR", "my-var", "OTHER_VAR", "app-1", "app1", "8080")
+
+	assert.Equal(t, `"MY_VAR"`, keys.Key("MY_VAR"))
+	assert.Equal(t, `"my-var"`, keys.Key("my-var"))
+	assert.Equal(t, "otherVar", keys.Key("OTHER_VAR"))
+	assert.Equal(t, `"app-1"`, keys.Key("app-1"))
+	assert.Equal(t, `"app1"`, keys.Key("app1"))
+	assert.Equal(t, `"8080"`, keys.Key("8080"))
+	assert.Equal(t, "unknownName", keys.Key("unknown-name"))
+
+	assert.Equal(t, "my-var", keys.Original(`"my-var"`))
+	assert.Equal(t, "OTHER_VAR", keys.Original("otherVar"))
+	assert.Equal(t, map[string][]string{
+		"myVar": {"MY_VAR", "my-var"},
+		"app1":  {"app-1", "app1"},
+	}, keys.Clashes())
+}
+
+func TestNameKeys_ContainerKey(t *testing.T) {
+	specMap := map[string]interface{}{
+		"containers":     []interface{}{map[string]interface{}{"name": `"app-1"`}},
+		"initContainers": []interface{}{map[string]interface{}{"name": `"app1"`}, map[string]interface{}{"name": "init-db"}},
+	}
+	keys := ContainerKeys(specMap)
+	// quoted and plain container names map to the same keys
+	assert.Equal(t, `"app-1"`, keys.ContainerKey(map[string]interface{}{"name": `"app-1"`}))
+	assert.Equal(t, `"app1"`, keys.ContainerKey(map[string]interface{}{"name": "app1"}))
+	assert.Equal(t, "initDb", keys.ContainerKey(map[string]interface{}{"name": `"init-db"`}))
+}
+
+func TestValues_AddQuotedKey(t *testing.T) {
+	values := NewValues()
+	ref, err := values.Add(ast.NewIdent("string"), "a", "app", `"MY_VAR"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `#config.app."MY_VAR"`, ref)
+	ref, err = values.Add(ast.NewIdent("string"), "b", "app", `"my-var"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `#config.app."my-var"`, ref)
+
+	assert.Equal(t, map[string]interface{}{"MY_VAR": "a", "my-var": "b"}, values.Values["app"])
+	cfg, err := format.Node(values.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(cfg), `"my-var": string`)
+	assert.Contains(t, string(cfg), `MY_VAR:   string`)
+}
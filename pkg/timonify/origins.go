@@ -0,0 +1,156 @@
+package timonify
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrValueCollision - two objects set different values at the same #config path.
+var ErrValueCollision = fmt.Errorf("value collision")
+
+// MergeFrom merges values of the object with given origin, e.g. Deployment/worker, into current instance.
+// Origins of merged values are recorded, so objects setting different values at the same path are reported
+// with ErrValueCollision instead of silently overwriting each other. Equal values, e.g. module-wide settings
+// shared by several objects, do not collide, they are recorded as shared values instead, see SharedValues.
+// Different #config schemas of the same path are reported with ErrValueCollision as well.
+func (v *Values) MergeFrom(origin string, values *Values) error {
+	if v.origins == nil {
+		v.origins = map[string]string{}
+	}
+	if err := v.checkCollisions(origin, v.Values, values.Values, nil); err != nil {
+		return err
+	}
+	if err := v.checkConfigCollisions(origin, v.Config, values.Config, nil); err != nil {
+		return err
+	}
+	return v.Merge(values)
+}
+
+func (v *Values) checkConfigCollisions(origin string, current, added *ast.StructLit, path []string) error {
+	for _, elt := range added.Elts {
+		field, ok := elt.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name := labelName(field.Label)
+		fieldPath := append(append([]string{}, path...), name)
+		existing := findField(current, name)
+		if existing == nil {
+			if _, isStruct := field.Value.(*ast.StructLit); !isStruct {
+				if _, ok := v.origins[strings.Join(fieldPath, ".")]; !ok {
+					v.origins[strings.Join(fieldPath, ".")] = origin
+				}
+			}
+			continue
+		}
+		existingStruct, existingIsStruct := existing.Value.(*ast.StructLit)
+		addedStruct, addedIsStruct := field.Value.(*ast.StructLit)
+		if existingIsStruct && addedIsStruct {
+			if err := v.checkConfigCollisions(origin, existingStruct, addedStruct, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sameExpr(existing.Value, field.Value) {
+			existingSchema, _ := format.Node(existing.Value)
+			addedSchema, _ := format.Node(field.Value)
+			return fmt.Errorf("%w at #config.%s: %s defines %s, %s defines %s", ErrValueCollision,
+				labelPath(fieldPath), v.origin(fieldPath), existingSchema, origin, addedSchema)
+		}
+	}
+	return nil
+}
+
+func (v *Values) checkCollisions(origin string, current, added map[string]interface{}, path []string) error {
+	keys := make([]string, 0, len(added))
+	for k := range added {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fieldPath := append(append([]string{}, path...), k)
+		existing, exists := current[k]
+		if !exists {
+			v.recordOrigins(origin, added[k], fieldPath)
+			continue
+		}
+		existingMap, existingIsMap := existing.(map[string]interface{})
+		addedMap, addedIsMap := added[k].(map[string]interface{})
+		if existingIsMap && addedIsMap {
+			if err := v.checkCollisions(origin, existingMap, addedMap, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if existingIsMap == addedIsMap && reflect.DeepEqual(existing, added[k]) {
+			v.recordShared(origin, fieldPath)
+			continue
+		}
+		return fmt.Errorf("%w at #config.%s: %s sets %v, %s sets %v", ErrValueCollision,
+			labelPath(fieldPath), v.origin(fieldPath), existing, origin, added[k])
+	}
+	return nil
+}
+
+func (v *Values) recordOrigins(origin string, value interface{}, path []string) {
+	if m, ok := value.(map[string]interface{}); ok {
+		for k, val := range m {
+			v.recordOrigins(origin, val, append(append([]string{}, path...), k))
+		}
+		if len(m) != 0 {
+			return
+		}
+	}
+	v.origins[strings.Join(path, ".")] = origin
+}
+
+// SharedValue - value set to the same value by several objects, changing it in #config affects all of them.
+type SharedValue struct {
+	Path    string
+	Origins []string
+}
+
+// SharedValues returns values which several merged objects set to the same value, sorted by path.
+func (v *Values) SharedValues() []SharedValue {
+	res := make([]SharedValue, 0, len(v.shared))
+	for path, origins := range v.shared {
+		res = append(res, SharedValue{Path: path, Origins: origins})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+func (v *Values) recordShared(origin string, path []string) {
+	if v.shared == nil {
+		v.shared = map[string][]string{}
+	}
+	key := "#config." + labelPath(path)
+	if len(v.shared[key]) == 0 {
+		v.shared[key] = []string{v.origin(path)}
+	}
+	if !slices.Contains(v.shared[key], origin) {
+		v.shared[key] = append(v.shared[key], origin)
+	}
+}
+
+// origin returns origin of the value at path or of its nearest nested value.
+func (v *Values) origin(path []string) string {
+	key := strings.Join(path, ".")
+	if origin, ok := v.origins[key]; ok {
+		return origin
+	}
+	for k, origin := range v.origins {
+		if strings.HasPrefix(k, key+".") {
+			return origin
+		}
+	}
+	return "unknown object"
+}
@@ -0,0 +1,89 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValues_MergeFrom(t *testing.T) {
+	objValues := func(replicas int64) *Values {
+		v := NewValues()
+		_, err := v.Add(ast.NewIdent("int"), replicas, "worker", "replicas")
+		assert.NoError(t, err)
+		_, err = v.Add(ast.NewList(&ast.Ellipsis{}), []interface{}{}, "imagePullSecrets")
+		assert.NoError(t, err)
+		return v
+	}
+
+	t.Run("equal values", func(t *testing.T) {
+		merged := NewValues()
+		assert.NoError(t, merged.MergeFrom("Deployment/worker", objValues(3)))
+		assert.NoError(t, merged.MergeFrom("ReplicaSet/worker", objValues(3)))
+		assert.NoError(t, merged.MergeFrom("Rollout/worker", objValues(3)))
+		assert.Equal(t, int64(3), merged.Values["worker"].(map[string]interface{})["replicas"])
+		// every shared path is reported with all objects setting it
+		assert.Equal(t, []SharedValue{
+			{Path: "#config.imagePullSecrets", Origins: []string{"Deployment/worker", "ReplicaSet/worker", "Rollout/worker"}},
+			{Path: "#config.worker.replicas", Origins: []string{"Deployment/worker", "ReplicaSet/worker", "Rollout/worker"}},
+		}, merged.SharedValues())
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		merged := NewValues()
+		assert.NoError(t, merged.MergeFrom("Deployment/worker", objValues(3)))
+		err := merged.MergeFrom("ReplicaSet/worker", objValues(1))
+		assert.ErrorIs(t, err, ErrValueCollision)
+		assert.EqualError(t, err, "value collision at #config.worker.replicas: Deployment/worker sets 3, ReplicaSet/worker sets 1")
+		// values are not overwritten on collision
+		assert.Equal(t, int64(3), merged.Values["worker"].(map[string]interface{})["replicas"])
+	})
+
+	t.Run("value and struct", func(t *testing.T) {
+		merged := NewValues()
+		assert.NoError(t, merged.MergeFrom("Deployment/worker", objValues(3)))
+		other := NewValues()
+		_, err := other.Add(ast.NewIdent("int"), 1, "worker")
+		assert.NoError(t, err)
+		assert.EqualError(t, merged.MergeFrom("Service/worker", other),
+			"value collision at #config.worker: Deployment/worker sets map[replicas:3], Service/worker sets 1")
+	})
+}
+
+func TestValues_MergeFrom_lists(t *testing.T) {
+	objValues := func() *Values {
+		v := NewValues()
+		_, err := v.Add(nil, []interface{}{`"extra"`}, "worker", "extraArgs")
+		assert.NoError(t, err)
+		return v
+	}
+	deployment, pod := objValues(), objValues()
+
+	merged := NewValues()
+	assert.NoError(t, merged.MergeFrom("Deployment/worker", deployment))
+	assert.NoError(t, merged.MergeFrom("Pod/worker", pod))
+	assert.Equal(t, []interface{}{`"extra"`}, merged.Values["worker"].(map[string]interface{})["extraArgs"])
+
+	// merged objects values are not modified, so they can be merged again
+	module := NewValues()
+	assert.NoError(t, module.Merge(deployment))
+	assert.NoError(t, module.Merge(pod))
+	assert.Equal(t, []interface{}{`"extra"`}, module.Values["worker"].(map[string]interface{})["extraArgs"])
+	assert.Equal(t, []interface{}{`"extra"`}, deployment.Values["worker"].(map[string]interface{})["extraArgs"])
+}
+
+func TestValues_MergeFrom_schemaCollision(t *testing.T) {
+	merged := NewValues()
+	deployment := NewValues()
+	_, err := deployment.Add(ast.NewIdent("int"), 3, "worker", "replicas")
+	assert.NoError(t, err)
+	assert.NoError(t, merged.MergeFrom("Deployment/worker", deployment))
+
+	rollout := NewValues()
+	err = rollout.AddConfig(ast.NewIdent("string"), false, "worker", "replicas")
+	assert.NoError(t, err)
+	err = merged.MergeFrom("Rollout/worker", rollout)
+	assert.ErrorIs(t, err, ErrValueCollision)
+	assert.EqualError(t, err, "value collision at #config.worker.replicas: Deployment/worker defines int, Rollout/worker defines string")
+}
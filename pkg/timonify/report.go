@@ -0,0 +1,70 @@
+package timonify
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report - conversion report, lists what needs user attention in the generated module.
+type Report struct {
+	// Sensitive - values kept out of values.cue, users have to provide them.
+	Sensitive []SensitiveValue
+	// Shared - values set by several objects, changing them affects all of them.
+	Shared []SharedValue
+}
+
+// Empty - returns true if there is nothing to report.
+func (r *Report) Empty() bool {
+	return len(r.Sensitive) == 0 && len(r.Shared) == 0
+}
+
+// Merge - adds entries of the other report which are not reported yet, e.g. of other module environments.
+func (r *Report) Merge(other *Report) {
+	for _, s := range other.Sensitive {
+		if !slices.Contains(r.Sensitive, s) {
+			r.Sensitive = append(r.Sensitive, s)
+		}
+	}
+	for _, s := range other.Shared {
+		reported := slices.ContainsFunc(r.Shared, func(shared SharedValue) bool {
+			return shared.Path == s.Path && slices.Equal(shared.Origins, s.Origins)
+		})
+		if !reported {
+			r.Shared = append(r.Shared, s)
+		}
+	}
+}
+
+// Write - writes human-readable report.
+func (r *Report) Write(writer io.Writer) error {
+	if len(r.Sensitive) != 0 {
+		if _, err := fmt.Fprintln(writer, "Sensitive values are required and kept out of values.cue:"); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		for _, s := range r.Sensitive {
+			if _, err := fmt.Fprintf(w, "  %s\t%s\t%s\n", s.Path, s.Origin, s.Reason); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(r.Shared) != 0 {
+		if _, err := fmt.Fprintln(writer, "Values set by several objects, changing them affects all of them:"); err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		for _, s := range r.Shared {
+			if _, err := fmt.Fprintf(w, "  %s\t%s\n", s.Path, strings.Join(s.Origins, ", ")); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package timonify
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport_Write(t *testing.T) {
+	var out bytes.Buffer
+	r := &Report{}
+	assert.True(t, r.Empty())
+	assert.NoError(t, r.Write(&out))
+	assert.Empty(t, out.String())
+
+	r.Sensitive = []SensitiveValue{
+		{Path: "#config.db.user", Origin: "Secret/db", Reason: "value of Secret"},
+		{Path: "#config.web.app.env.apiToken", Origin: "Deployment/web", Reason: `name contains "token"`},
+	}
+	assert.False(t, r.Empty())
+	assert.NoError(t, r.Write(&out))
+	assert.Equal(t, `Sensitive values are required and kept out of values.cue:
+  #config.db.user               Secret/db       value of Secret
+  #config.web.app.env.apiToken  Deployment/web  name contains "token"
+`, out.String())
+
+	out.Reset()
+	r.Shared = []SharedValue{{Path: "#config.imagePullSecrets", Origins: []string{"Deployment/web", "Deployment/worker"}}}
+	assert.NoError(t, r.Write(&out))
+	assert.Contains(t, out.String(), `Values set by several objects, changing them affects all of them:
+  #config.imagePullSecrets  Deployment/web, Deployment/worker
+`)
+}
+
+func TestReport_Merge(t *testing.T) {
+	user := SensitiveValue{Path: "#config.db.user", Origin: "Secret/db", Reason: "value of Secret"}
+	token := SensitiveValue{Path: "#config.web.app.env.apiToken", Origin: "Deployment/web", Reason: `name contains "token"`}
+	r := &Report{Sensitive: []SensitiveValue{user}}
+	r.Merge(&Report{Sensitive: []SensitiveValue{user, token}})
+	assert.Equal(t, []SensitiveValue{user, token}, r.Sensitive)
+
+	shared := SharedValue{Path: "#config.imagePullSecrets", Origins: []string{"Deployment/web", "Deployment/worker"}}
+	r.Merge(&Report{Shared: []SharedValue{shared}})
+	r.Merge(&Report{Shared: []SharedValue{shared}})
+	assert.Equal(t, []SharedValue{shared}, r.Shared)
+}
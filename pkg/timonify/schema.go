@@ -0,0 +1,150 @@
+package timonify
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// inferSchema - infers default config schema from the value added without explicit schema, so every value in
+// values.cue has a corresponding #Config field: *value | string, *n | int, *b | bool, open structs for maps and
+// [...T] for slices.
+func inferSchema(value interface{}) (ast.Expr, error) {
+	expr, err := parser.ParseExpr("", schemaSource(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to infer schema", err)
+	}
+	return expr, nil
+}
+
+func schemaSource(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var res strings.Builder
+		res.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&res, "%s: %s\n", LabelSource(k), schemaSource(value[k]))
+		}
+		res.WriteString("...\n}")
+		return res.String()
+	case map[string]string:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[k] = v
+		}
+		return schemaSource(m)
+	case []string:
+		return "[...string]"
+	case []interface{}:
+		return "[..." + elemType(value) + "]"
+	}
+	if lit := literalSource(value); lit != "" {
+		return "*" + lit + " | " + typeName(value)
+	}
+	return typeName(value)
+}
+
+// elemType - infers type of slice elements, elements of different types are not constrained.
+func elemType(list []interface{}) string {
+	var res string
+	for _, elem := range list {
+		typ := typeName(elem)
+		if res != "" && typ != res {
+			return "_"
+		}
+		res = typ
+	}
+	if res == "" {
+		return "_"
+	}
+	return res
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "int"
+	case float32, float64:
+		return "number"
+	case map[string]interface{}, map[string]string:
+		return "{...}"
+	case []interface{}, []string:
+		return "[...]"
+	}
+	return "_"
+}
+
+// literalSource - returns value literal used as default, string values are expected to be quoted CUE strings.
+func literalSource(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		if expr, err := parser.ParseExpr("", value); err == nil {
+			if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				return value
+			}
+		}
+		return strconv.Quote(value)
+	case bool:
+		return strconv.FormatBool(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return ""
+}
+
+// LabelSource - returns identifier label if name is a valid regular identifier, quoted label otherwise.
+func LabelSource(name string) string {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "#") && !strings.HasPrefix(name, "_") {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func newLabel(name string) ast.Label {
+	if LabelSource(name) == name {
+		return ast.NewIdent(name)
+	}
+	return ast.NewString(name)
+}
+
+// labelName - returns field name for both identifier and quoted labels.
+func labelName(label ast.Label) string {
+	name, _, err := ast.LabelName(label)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// hasConfig returns true if config already defines the field with given name or one of its parents is
+// defined by non-struct schema.
+func hasConfig(config ast.Node, name ...string) bool {
+	current := config
+	for _, n := range name {
+		if _, ok := current.(*ast.StructLit); !ok {
+			return true
+		}
+		field := findField(current, n)
+		if field == nil {
+			return false
+		}
+		current = field.Value
+	}
+	return true
+}
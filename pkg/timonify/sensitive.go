@@ -0,0 +1,200 @@
+package timonify
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"github.com/iancoleman/strcase"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+)
+
+// sensitiveAttr - attribute of #config fields with sensitive values.
+const sensitiveAttr = "@sensitive()"
+
+// DefaultSensitiveWords - words of value names marking them as sensitive, e.g. dbPassword or API_TOKEN.
+var DefaultSensitiveWords = []string{"password", "passwd", "token", "credentials"}
+
+// DefaultSensitiveSuffixes - last words of value names marking them as sensitive, e.g. SIGNING_KEY or clientSecret.
+// These words are too common to mark values anywhere in the name, e.g. secretName or secretKeyRef, or as the whole
+// name, e.g. ConfigMap key.
+var DefaultSensitiveSuffixes = []string{"key", "secret"}
+
+// DefaultBenignNames - value names ending with DefaultSensitiveSuffixes which are known not to be sensitive,
+// compared ignoring case and separators, e.g. topologyKey of pod affinity.
+var DefaultBenignNames = []string{"topologyKey", "publicKey"}
+
+// Sensitivity - detects sensitive values, e.g. credentials, which must not be published with the module.
+type Sensitivity struct {
+	words    map[string]bool
+	suffixes map[string]bool
+	// benign - normalized DefaultBenignNames.
+	benign map[string]bool
+	// names - user defined value names and dot separated #config paths.
+	names map[string]bool
+}
+
+// NewSensitivity - returns detector of values named with DefaultSensitiveWords, DefaultSensitiveSuffixes or
+// user defined names. Names are either value names, e.g. DB_HOST, or dot separated #config paths,
+// e.g. web.app.env.dbHost.
+func NewSensitivity(names ...string) *Sensitivity {
+	res := &Sensitivity{words: map[string]bool{}, suffixes: map[string]bool{}, benign: map[string]bool{}, names: map[string]bool{}}
+	for _, w := range DefaultSensitiveWords {
+		res.words[w] = true
+	}
+	for _, w := range DefaultSensitiveSuffixes {
+		res.suffixes[w] = true
+	}
+	for _, n := range DefaultBenignNames {
+		res.benign[normalizedName(n)] = true
+	}
+	for _, n := range names {
+		res.names[n] = true
+		if !strings.Contains(n, ".") {
+			// manifest names, e.g. env var names, are normalized in #config
+			res.names[LabelName(NameKey(n))] = true
+		}
+	}
+	return res
+}
+
+// SensitiveValue - value kept out of values.cue, its #config field is required.
+type SensitiveValue struct {
+	// Path - #config path of the value, e.g. #config.web.app.env.dbPassword.
+	Path string
+	// Origin - object the value comes from, <kind>/<name>.
+	Origin string
+	// Reason - why the value is sensitive.
+	Reason string
+}
+
+// reason returns why the value at path is sensitive or empty string if it is not.
+func (s *Sensitivity) reason(path []string) string {
+	name := path[len(path)-1]
+	if s.names[name] || s.names[strings.Join(path, ".")] {
+		return "listed as sensitive"
+	}
+	words := strings.Split(strcase.ToSnake(name), "_")
+	for _, word := range words {
+		if s.words[word] {
+			return fmt.Sprintf("name contains %q", word)
+		}
+	}
+	last := words[len(words)-1]
+	if len(words) > 1 && s.suffixes[last] && !s.benign[normalizedName(name)] {
+		return fmt.Sprintf("name ends with %q", last)
+	}
+	return ""
+}
+
+// normalizedName returns lower case name without separators, e.g. apikey for API_KEY or api-key.
+func normalizedName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// MarkSensitive - removes sensitive values from values, their #config fields become required, lose defaults
+// and get @sensitive() attribute. All values of Secret objects are sensitive.
+// origin is the object the values come from, <kind>/<name>.
+func (v *Values) MarkSensitive(detector *Sensitivity, origin string, secret bool) ([]SensitiveValue, error) {
+	var res []SensitiveValue
+	var walk func(values map[string]interface{}, path []string) error
+	walk = func(values map[string]interface{}, path []string) error {
+		for _, k := range sortedKeys(values) {
+			valuePath := append(append([]string{}, path...), k)
+			if m, ok := values[k].(map[string]interface{}); ok {
+				if err := walk(m, valuePath); err != nil {
+					return err
+				}
+				if len(m) == 0 {
+					delete(values, k)
+				}
+				continue
+			}
+			reason := detector.reason(valuePath)
+			if reason == "" && secret {
+				reason = "value of Secret"
+			}
+			if reason == "" {
+				continue
+			}
+			if err := v.requireSensitive(valuePath, values[k]); err != nil {
+				return fmt.Errorf("%w: unable to mark %s as sensitive", err, labelPath(valuePath))
+			}
+			delete(values, k)
+			res = append(res, SensitiveValue{Path: "#config." + labelPath(valuePath), Origin: origin, Reason: reason})
+		}
+		return nil
+	}
+	if err := walk(v.Values, nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// requireSensitive makes #config field at path required without default and marks it with @sensitive().
+func (v *Values) requireSensitive(path []string, value interface{}) error {
+	field, tail := findNestedField(v.Config, path)
+	if len(tail) > 0 {
+		parent := v.Config
+		if field != nil {
+			if s, ok := field.Value.(*ast.StructLit); ok {
+				parent = s
+			} else {
+				// fields of non-struct schemas, e.g. maps, are added by unification
+				parent = ast.NewStruct()
+				field.Value = ast.NewBinExpr(token.AND, parensIfDisjunction(field.Value), parent)
+			}
+		}
+		if err := setNestedCueField(parent, cueformat.MustParse(typeName(value)), false, tail...); err != nil {
+			return err
+		}
+		for _, elt := range parent.Elts {
+			ast.SetRelPos(elt, token.Newline)
+		}
+		field, _ = findNestedField(parent, tail)
+	}
+	field.Value = withoutDefaults(field.Value, value)
+	field.Optional = token.NoPos
+	field.Constraint = token.NOT
+	for _, attr := range field.Attrs {
+		if attr.Text == sensitiveAttr {
+			return nil
+		}
+	}
+	field.Attrs = append(field.Attrs, &ast.Attribute{Text: sensitiveAttr})
+	return nil
+}
+
+// withoutDefaults removes default disjuncts of the schema, e.g. *"abc" | string becomes string.
+func withoutDefaults(schema ast.Expr, value interface{}) ast.Expr {
+	var res ast.Expr
+	var collect func(expr ast.Expr)
+	collect = func(expr ast.Expr) {
+		switch e := expr.(type) {
+		case *ast.BinaryExpr:
+			if e.Op == token.OR {
+				collect(e.X)
+				collect(e.Y)
+				return
+			}
+		case *ast.UnaryExpr:
+			if e.Op == token.MUL {
+				return
+			}
+		case *ast.ParenExpr:
+			collect(e.X)
+			return
+		}
+		if res == nil {
+			res = expr
+		} else {
+			res = ast.NewBinExpr(token.OR, res, expr)
+		}
+	}
+	collect(schema)
+	if res == nil {
+		return cueformat.MustParse(typeName(value))
+	}
+	return res
+}
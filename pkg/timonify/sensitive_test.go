@@ -0,0 +1,112 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+)
+
+func TestValues_MarkSensitive(t *testing.T) {
+	v := NewValues()
+	for name, value := range map[string]string{
+		"DB_PASSWORD":   `"hunter2"`,
+		"API_TOKEN":     `"abc"`,
+		"DB_HOST":       `"db"`,
+		"MONKEY":        `"banana"`,
+		"LOG_LEVEL":     `"info"`,
+		"PRIVATE_KEY":   `"pk"`,
+		"SIGNING_KEY":   `"sk"`,
+		"CLIENT_SECRET": `"cs"`,
+	} {
+		_, err := v.Add(ast.NewIdent("string"), value, "web", "app", "env", name)
+		assert.NoError(t, err)
+	}
+	_, err := v.Add(nil, `"https://api"`, "web", "apiUrl")
+	assert.NoError(t, err)
+	// names with common words are not sensitive by themselves
+	_, err = v.Add(nil, `"tls-cert"`, "web", "secretName")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, map[string]interface{}{"name": `"db"`, "key": `"host"`}, "web", "secretKeyRef")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, `"value"`, "web", "data", "key")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, `"zone"`, "web", "affinity", "topologyKey")
+	assert.NoError(t, err)
+	// values under map schemas have no #config fields of their own
+	assert.NoError(t, v.AddConfig(cueformat.MustParse("{[string]: string}"), false, "web", "annotations"))
+	_, err = v.Add(nil, map[string]interface{}{"apiKey": `"s"`, "team": `"web"`}, "web", "annotations")
+	assert.NoError(t, err)
+	assert.NoError(t, v.AddConfig(ast.NewSel(ast.NewIdent("timoniv1"), "#Labels"), false, "web", "labels"))
+	_, err = v.Add(nil, map[string]interface{}{"token": `"t"`}, "web", "labels")
+	assert.NoError(t, err)
+
+	sensitive, err := v.MarkSensitive(NewSensitivity("DB_HOST", "web.apiUrl"), "Deployment/web", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []SensitiveValue{
+		{Path: "#config.web.annotations.apiKey", Origin: "Deployment/web", Reason: `name ends with "key"`},
+		{Path: "#config.web.apiUrl", Origin: "Deployment/web", Reason: "listed as sensitive"},
+		{Path: "#config.web.app.env.apiToken", Origin: "Deployment/web", Reason: `name contains "token"`},
+		{Path: "#config.web.app.env.clientSecret", Origin: "Deployment/web", Reason: `name ends with "secret"`},
+		{Path: "#config.web.app.env.dbHost", Origin: "Deployment/web", Reason: "listed as sensitive"},
+		{Path: "#config.web.app.env.dbPassword", Origin: "Deployment/web", Reason: `name contains "password"`},
+		{Path: "#config.web.app.env.privateKey", Origin: "Deployment/web", Reason: `name ends with "key"`},
+		{Path: "#config.web.app.env.signingKey", Origin: "Deployment/web", Reason: `name ends with "key"`},
+		{Path: "#config.web.labels.token", Origin: "Deployment/web", Reason: `name contains "token"`},
+	}, sensitive)
+	assert.Equal(t, map[string]interface{}{
+		"web": map[string]interface{}{
+			"annotations": map[string]interface{}{"team": `"web"`},
+			"app": map[string]interface{}{
+				"env": map[string]interface{}{"logLevel": `"info"`, "monkey": `"banana"`},
+			},
+			"secretName":   `"tls-cert"`,
+			"secretKeyRef": map[string]interface{}{"name": `"db"`, "key": `"host"`},
+			"data":         map[string]interface{}{"key": `"value"`},
+			"affinity":     map[string]interface{}{"topologyKey": `"zone"`},
+		},
+	}, v.Values)
+
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `dbPassword!:   string @sensitive()`)
+	assert.Contains(t, string(b), `monkey:        string`)
+	// inferred defaults are removed
+	assert.Contains(t, string(b), `apiUrl!:    string @sensitive()`)
+	assert.Contains(t, string(b), `signingKey!:   string @sensitive()`)
+	assert.Contains(t, string(b), `annotations: {
+			[string]: string
+			apiKey!:  string @sensitive()
+		}`)
+	assert.Contains(t, string(b), `labels: timoniv1.#Labels & {
+			token!: string @sensitive()
+		}`)
+}
+
+func TestValues_MarkSensitiveSecret(t *testing.T) {
+	v := NewValues()
+	_, err := v.Add(nil, `"admin"`, "db", "user")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, int64(5432), "db", "port")
+	assert.NoError(t, err)
+
+	sensitive, err := v.MarkSensitive(NewSensitivity(), "Secret/db", true)
+	assert.NoError(t, err)
+	assert.Len(t, sensitive, 2)
+	assert.Empty(t, v.Values)
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `port!: int    @sensitive()`)
+	assert.Contains(t, string(b), `user!: string @sensitive()`)
+
+	// already marked fields are not marked twice
+	_, err = v.Add(nil, `"admin"`, "db", "user")
+	assert.NoError(t, err)
+	_, err = v.MarkSensitive(NewSensitivity(), "Secret/db", true)
+	assert.NoError(t, err)
+	b, err = format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `user!: string @sensitive()`)
+}
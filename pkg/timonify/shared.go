@@ -0,0 +1,215 @@
+package timonify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"github.com/iancoleman/strcase"
+	cueformat "github.com/syndicut/timonify/pkg/cue"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// sharedValues - container values which can be shared by workloads, relative to #config.<objName>.<containerName>.
+// Empty name stands for every field of the parent, e.g. every env var.
+var sharedValues = [][]string{
+	{"image", "repository"},
+	{"env", ""},
+	{"resources"},
+}
+
+// containerValue - value of the workload container.
+type containerValue struct {
+	workload, container string
+	value               interface{}
+}
+
+// HoistShared - moves container values equal across workloads, e.g. image repositories, env vars and resources,
+// to module-wide #config fields: #config.image.repository, #config.env.<name> and #config.resources.
+// Container fields default to the shared ones and keep their own values if they differ.
+func (v *Values) HoistShared() error {
+	shared := map[string][]containerValue{}
+	paths := map[string][]string{}
+	for _, workload := range sortedKeys(v.Values) {
+		containers, ok := v.Values[workload].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, container := range sortedKeys(containers) {
+			values, ok := containers[container].(map[string]interface{})
+			if !ok || values["image"] == nil {
+				continue
+			}
+			for _, path := range containerPaths(values) {
+				value, found, _ := unstructured.NestedFieldNoCopy(values, path...)
+				if !found {
+					continue
+				}
+				key := strings.Join(path, ".")
+				paths[key] = path
+				shared[key] = append(shared[key], containerValue{workload: workload, container: container, value: value})
+			}
+		}
+	}
+	lets := map[string]bool{}
+	for _, key := range sortedKeys(paths) {
+		path := paths[key]
+		value, ok := sharedValue(shared[key])
+		if !ok || v.definesTopLevel(path[0], lets) {
+			continue
+		}
+		if err := v.hoist(path, value, shared[key], lets); err != nil {
+			return fmt.Errorf("%w: unable to hoist shared value %s", err, key)
+		}
+	}
+	return nil
+}
+
+// hoist adds shared field at path and makes container fields default to it.
+func (v *Values) hoist(path []string, value interface{}, values []containerValue, lets map[string]bool) error {
+	alias := "shared" + strcase.ToCamel(path[0])
+	ref := alias
+	if len(path) > 1 {
+		ref += "." + labelPath(path[1:])
+	}
+	var schema ast.Expr
+	for _, c := range values {
+		containerPath := append([]string{c.workload, c.container}, path...)
+		field, tail := findNestedField(v.Config, containerPath)
+		if field == nil {
+			continue
+		}
+		if len(tail) == 0 {
+			if schema == nil {
+				var err error
+				if schema, err = cloneExpr(field.Value); err != nil {
+					return err
+				}
+			}
+			field.Value = ast.NewBinExpr(token.OR, &ast.UnaryExpr{Op: token.MUL, X: cueformat.MustParse(ref)},
+				parensIfDisjunction(field.Value))
+		} else {
+			override := ast.NewStruct()
+			defaultRef := fmt.Sprintf("*%s | %s", ref, typeName(value))
+			if err := setNestedCueField(override, cueformat.MustParse(defaultRef), false, tail...); err != nil {
+				return err
+			}
+			field.Value = ast.NewBinExpr(token.AND, parensIfDisjunction(field.Value), override)
+		}
+		if reflect.DeepEqual(c.value, value) {
+			removeNestedValue(v.Values, containerPath)
+		}
+	}
+	if schema == nil {
+		schema = ast.NewIdent(typeName(value))
+	}
+	if err := setNestedCueField(v.Config, schema, false, path...); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(v.Values, value, path...); err != nil {
+		return err
+	}
+	if !lets[path[0]] {
+		// container fields can not reference shared ones directly, their parents have the same names
+		v.Config.Elts = append(v.Config.Elts, &ast.LetClause{Ident: ast.NewIdent(alias), Expr: ast.NewIdent(LabelSource(path[0]))})
+		lets[path[0]] = true
+	}
+	return nil
+}
+
+func cloneExpr(expr ast.Expr) (ast.Expr, error) {
+	src, err := format.Node(expr)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseExpr("", src)
+}
+
+// containerPaths returns paths of the container values which can be shared.
+func containerPaths(values map[string]interface{}) [][]string {
+	var res [][]string
+	for _, path := range sharedValues {
+		if path[len(path)-1] != "" {
+			res = append(res, path)
+			continue
+		}
+		parent, ok, _ := unstructured.NestedMap(values, path[:len(path)-1]...)
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(parent) {
+			res = append(res, append(append([]string{}, path[:len(path)-1]...), name))
+		}
+	}
+	return res
+}
+
+// sharedValue returns the value set by the largest number of workloads, if at least two of them set it.
+func sharedValue(values []containerValue) (interface{}, bool) {
+	var (
+		res   interface{}
+		count int
+	)
+	for _, candidate := range values {
+		workloads := map[string]bool{}
+		for _, c := range values {
+			if reflect.DeepEqual(c.value, candidate.value) {
+				workloads[c.workload] = true
+			}
+		}
+		if len(workloads) > count {
+			res, count = candidate.value, len(workloads)
+		}
+	}
+	return res, count > 1
+}
+
+// definesTopLevel returns true if #config already has the field not created by hoisting, e.g. a workload
+// with the same name.
+func (v *Values) definesTopLevel(name string, lets map[string]bool) bool {
+	if lets[name] {
+		return false
+	}
+	_, exists := v.Values[name]
+	return exists || findField(v.Config, name) != nil
+}
+
+// findNestedField returns the deepest existing field at path and the rest of the path.
+func findNestedField(config *ast.StructLit, path []string) (*ast.Field, []string) {
+	var res *ast.Field
+	current := ast.Node(config)
+	for i, name := range path {
+		field := findField(current, name)
+		if field == nil {
+			return res, path[i:]
+		}
+		res, current = field, field.Value
+	}
+	return res, nil
+}
+
+// removeNestedValue removes value at path and its parents left empty, up to the container values.
+func removeNestedValue(values map[string]interface{}, path []string) {
+	unstructured.RemoveNestedField(values, path...)
+	for i := len(path) - 1; i > 2; i-- {
+		parent, ok, _ := unstructured.NestedMap(values, path[:i]...)
+		if !ok || len(parent) != 0 {
+			return
+		}
+		unstructured.RemoveNestedField(values, path[:i]...)
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package timonify
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValues_HoistShared(t *testing.T) {
+	v := NewValues()
+	for _, c := range []struct {
+		workload, repository, logLevel, mode string
+	}{
+		{workload: "web", repository: `"ghcr.io/acme/app"`, logLevel: `"info"`, mode: `"web"`},
+		{workload: "worker", repository: `"ghcr.io/acme/app"`, logLevel: `"info"`, mode: `"worker"`},
+		{workload: "debug", repository: `"ghcr.io/acme/debug"`, logLevel: `"debug"`, mode: `"web"`},
+	} {
+		err := v.AddConfig(ast.NewSel(ast.NewIdent("timoniv1"), "#Image"), true, c.workload, "app", "image")
+		assert.NoError(t, err)
+		_, err = v.Add(nil, c.repository, c.workload, "app", "image", "repository")
+		assert.NoError(t, err)
+		_, err = v.Add(ast.NewIdent("string"), c.logLevel, c.workload, "app", "env", "LOG_LEVEL")
+		assert.NoError(t, err)
+		_, err = v.Add(ast.NewIdent("string"), c.mode, c.workload, "app", "env", "MODE")
+		assert.NoError(t, err)
+	}
+	_, err := v.Add(ast.NewIdent("string"), `"x"`, "env", "enabled")
+	assert.NoError(t, err)
+
+	assert.NoError(t, v.HoistShared())
+
+	assert.Equal(t, map[string]interface{}{"repository": `"ghcr.io/acme/app"`}, v.Values["image"])
+	// env is defined by a workload, so env vars are not hoisted
+	assert.Equal(t, map[string]interface{}{"enabled": `"x"`}, v.Values["env"])
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"repository": `"ghcr.io/acme/debug"`},
+		"env":   map[string]interface{}{"logLevel": `"debug"`, "mode": `"web"`},
+	}, v.Values["debug"].(map[string]interface{})["app"])
+	assert.Equal(t, map[string]interface{}{
+		"env": map[string]interface{}{"logLevel": `"info"`, "mode": `"web"`},
+	}, v.Values["web"].(map[string]interface{})["app"])
+
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `image!: timoniv1.#Image & {
+				repository: *sharedImage.repository | string
+			}`)
+	assert.Contains(t, string(b), `image: {
+		repository: string
+	}
+	let sharedImage = image`)
+}
+
+func TestValues_HoistSharedEnv(t *testing.T) {
+	v := NewValues()
+	for _, workload := range []string{"web", "worker"} {
+		err := v.AddConfig(ast.NewSel(ast.NewIdent("timoniv1"), "#Image"), true, workload, "app", "image")
+		assert.NoError(t, err)
+		_, err = v.Add(nil, `"1.0"`, workload, "app", "image", "tag")
+		assert.NoError(t, err)
+		_, err = v.Add(ast.NewIdent("string"), `"info"`, workload, "app", "env", "LOG_LEVEL")
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, v.HoistShared())
+
+	assert.Equal(t, map[string]interface{}{"logLevel": `"info"`}, v.Values["env"])
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": `"1.0"`},
+	}, v.Values["web"].(map[string]interface{})["app"])
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "logLevel: *sharedEnv.logLevel | string")
+	assert.Contains(t, string(b), "let sharedEnv = env")
+}
@@ -3,12 +3,10 @@ package timonify
 import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/token"
-	"dario.cat/mergo"
 	"fmt"
+	"reflect"
 	"strings"
 
-	"github.com/iancoleman/strcase"
-
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -18,6 +16,11 @@ type Values struct {
 	Config *ast.StructLit
 	// Values represents values for values.cue file.
 	Values map[string]interface{}
+
+	// origins - objects which set values, by value path, see MergeFrom.
+	origins map[string]string
+	// shared - objects which set equal values, by value path, see SharedValues.
+	shared map[string][]string
 }
 
 func NewValues() *Values {
@@ -28,17 +31,79 @@ func NewValues() *Values {
 }
 
 // Merge given values with current instance.
+// Given values are copied, so merged instances do not share nested maps and lists.
+// Lists are appended unless they are equal, values already set are kept.
 func (v *Values) Merge(values *Values) error {
-	if err := mergo.Merge(&v.Values, values.Values, mergo.WithAppendSlice); err != nil {
-		return fmt.Errorf("%w: unable to merge timoni values", err)
+	if v.Values == nil {
+		v.Values = map[string]interface{}{}
 	}
+	mergeMaps(v.Values, values.Values)
 	mergeStructLits(v.Config, values.Config)
 
 	return nil
 }
 
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, srcVal := range src {
+		dstVal, exists := dst[k]
+		if !exists || isEmptyValue(dstVal) {
+			dst[k] = deepCopyValue(srcVal)
+			continue
+		}
+		dstMap, dstIsMap := dstVal.(map[string]interface{})
+		srcMap, srcIsMap := srcVal.(map[string]interface{})
+		if dstIsMap && srcIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dstList, srcList := reflect.ValueOf(dstVal), reflect.ValueOf(srcVal)
+		if dstList.Kind() == reflect.Slice && dstList.Type() == srcList.Type() && !reflect.DeepEqual(dstVal, srcVal) {
+			dst[k] = reflect.AppendSlice(dstList, reflect.ValueOf(deepCopyValue(srcVal))).Interface()
+		}
+	}
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			res[k] = deepCopyValue(v)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(value))
+		for i, v := range value {
+			res[i] = deepCopyValue(v)
+		}
+		return res
+	case []string:
+		return append([]string{}, value...)
+	case map[string]string:
+		res := make(map[string]string, len(value))
+		for k, v := range value {
+			res[k] = v
+		}
+		return res
+	default:
+		return value
+	}
+}
+
 func (v *Values) AddConfig(config ast.Expr, required bool, name ...string) error {
-	name = toCamelCase(name)
+	name = labelNames(toCamelCase(name))
 	err := setNestedCueField(v.Config, config, required, name...)
 	if err != nil {
 		return fmt.Errorf("%w: unable to set nested cue field: %v", err, name)
@@ -46,7 +111,24 @@ func (v *Values) AddConfig(config ast.Expr, required bool, name ...string) error
 	return nil
 }
 
-// Add - adds given value to values and returns its timoni representation #config.<valueName>
+// AddOptionalConfig - adds optional config field <name>?: config without value in values.cue.
+// Returns its timoni representation #config.<valueName>.
+func (v *Values) AddOptionalConfig(config ast.Expr, name ...string) (string, error) {
+	name = toCamelCase(name)
+	labels := labelNames(name)
+	if err := setNestedCueField(v.Config, config, false, labels...); err != nil {
+		return "", fmt.Errorf("%w: unable to set nested cue field: %v", err, name)
+	}
+	parent := ast.Node(v.Config)
+	for _, n := range labels[:len(labels)-1] {
+		parent = findField(parent, n).Value
+	}
+	findField(parent, labels[len(labels)-1]).Constraint = token.OPTION
+	return "#config." + strings.Join(name, "."), nil
+}
+
+// Add - adds given value to values and returns its timoni representation #config.<valueName>.
+// If config is nil and #config has no such field yet, the schema is inferred from the value.
 func (v *Values) Add(config ast.Expr, value interface{}, name ...string) (string, error) {
 	name = toCamelCase(name)
 	switch val := value.(type) {
@@ -60,6 +142,13 @@ func (v *Values) Add(config ast.Expr, value interface{}, name ...string) (string
 		value = int64(val)
 	}
 
+	labels := labelNames(name)
+	if config == nil && !hasConfig(v.Config, labels...) {
+		var err error
+		if config, err = inferSchema(value); err != nil {
+			return "", err
+		}
+	}
 	if config != nil {
 		err := v.AddConfig(config, false, name...)
 		if err != nil {
@@ -70,11 +159,11 @@ func (v *Values) Add(config ast.Expr, value interface{}, name ...string) (string
 	var err error
 	switch value := value.(type) {
 	case []string:
-		err = unstructured.SetNestedStringSlice(v.Values, value, name...)
+		err = unstructured.SetNestedStringSlice(v.Values, value, labels...)
 	case map[string]string:
-		err = unstructured.SetNestedStringMap(v.Values, value, name...)
+		err = unstructured.SetNestedStringMap(v.Values, value, labels...)
 	default:
-		err = unstructured.SetNestedField(v.Values, value, name...)
+		err = unstructured.SetNestedField(v.Values, value, labels...)
 	}
 	if err != nil {
 		return "", fmt.Errorf("%w: unable to set value: %v", err, name)
@@ -102,12 +191,14 @@ func setNestedCueField(config ast.Node, value ast.Expr, required bool, name ...s
 		field := findField(currentNode, n)
 		if field == nil {
 			// If the field does not exist, create a new one
-			field = &ast.Field{Label: ast.NewIdent(n), Value: &ast.StructLit{}}
+			field = &ast.Field{Label: newLabel(n), Value: &ast.StructLit{}}
 			if required {
 				field.Constraint = token.NOT
 			}
 			// Add the new field to the current node
 			currentNode.(*ast.StructLit).Elts = append(currentNode.(*ast.StructLit).Elts, field)
+		} else if required {
+			field.Constraint = token.NOT
 		}
 		// Move to the next node
 		currentNode = field.Value
@@ -116,13 +207,17 @@ func setNestedCueField(config ast.Node, value ast.Expr, required bool, name ...s
 	// Add the value to the last field
 	lastField := findField(currentNode, name[len(name)-1])
 	if lastField == nil {
-		lastField = &ast.Field{Label: ast.NewIdent(name[len(name)-1]), Value: value}
+		lastField = &ast.Field{Label: newLabel(name[len(name)-1]), Value: value}
+		ast.SetRelPos(lastField, token.Newline)
 		if required {
 			lastField.Constraint = token.NOT
 		}
 		currentNode.(*ast.StructLit).Elts = append(currentNode.(*ast.StructLit).Elts, lastField)
 	} else {
 		lastField.Value = value
+		if required {
+			lastField.Constraint = token.NOT
+		}
 	}
 
 	return nil
@@ -131,7 +226,7 @@ func setNestedCueField(config ast.Node, value ast.Expr, required bool, name ...s
 func findField(node ast.Node, name string) *ast.Field {
 	if structLit, ok := node.(*ast.StructLit); ok {
 		for _, elt := range structLit.Elts {
-			if field, ok := elt.(*ast.Field); ok && field.Label.(*ast.Ident).Name == name {
+			if field, ok := elt.(*ast.Field); ok && labelName(field.Label) == name {
 				return field
 			}
 		}
@@ -172,17 +267,23 @@ func (v *Values) AddSecret(toBase64 bool, name ...string) (string, error) {
 	return res + " | quote }}", err
 }
 
+// toCamelCase normalizes names to #config keys, see NameKey. Quoted keys are kept as is.
 func toCamelCase(name []string) []string {
 	for i, n := range name {
-		camelCase := strcase.ToLowerCamel(n)
-		if n == strings.ToUpper(n) {
-			camelCase = strcase.ToLowerCamel(strings.ToLower(n))
-		}
-		name[i] = camelCase
+		name[i] = NameKey(n)
 	}
 	return name
 }
 
+// labelNames returns field names of #config keys.
+func labelNames(keys []string) []string {
+	res := make([]string, len(keys))
+	for i, k := range keys {
+		res[i] = LabelName(k)
+	}
+	return res
+}
+
 func mergeStructLits(struct1, struct2 *ast.StructLit) *ast.StructLit {
 	for _, elt2 := range struct2.Elts {
 		field2, ok := elt2.(*ast.Field)
@@ -195,7 +296,7 @@ func mergeStructLits(struct1, struct2 *ast.StructLit) *ast.StructLit {
 			if !ok {
 				continue
 			}
-			if field1.Label.(*ast.Ident).Name == field2.Label.(*ast.Ident).Name {
+			if labelName(field1.Label) == labelName(field2.Label) {
 				found = true
 				if struct1, ok := field1.Value.(*ast.StructLit); ok {
 					if struct2, ok := field2.Value.(*ast.StructLit); ok {
@@ -116,10 +116,55 @@ func Test_setNestedCueField(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.wantErr(t, setNestedCueField(tt.args.config, tt.args.value, tt.args.name...), fmt.Sprintf("setNestedCueField(%v, %v, %v)", tt.args.config, tt.args.value, tt.args.name))
+			tt.wantErr(t, setNestedCueField(tt.args.config, tt.args.value, false, tt.args.name...), fmt.Sprintf("setNestedCueField(%v, %v, %v)", tt.args.config, tt.args.value, tt.args.name))
 			b, err := format.Node(tt.args.config)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.want, string(b))
 		})
 	}
 }
+
+func TestValues_AddInferredSchema(t *testing.T) {
+	v := NewValues()
+	_, err := v.Add(nil, `"nginx"`, "app", "image", "repository")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, 3, "app", "replicas")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, true, "app", "enabled")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, map[string]interface{}{"disktype": `"ssd"`, "app.kubernetes.io/zone": `"a"`}, "app", "nodeSelector")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, []interface{}{`"--verbose"`}, "app", "args")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, []interface{}{}, "app", "extra")
+	assert.NoError(t, err)
+
+	// explicit schema is kept
+	err = v.AddConfig(ast.NewIdent("string"), false, "app", "name")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, `"app"`, "app", "name")
+	assert.NoError(t, err)
+	// non-struct parent schema covers nested values
+	err = v.AddConfig(ast.NewSel(ast.NewIdent("timoniv1"), "#Image"), false, "app", "image")
+	assert.NoError(t, err)
+	_, err = v.Add(nil, `"1.0"`, "app", "image", "tag")
+	assert.NoError(t, err)
+
+	b, err := format.Node(v.Config)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+	app: {
+		image:    timoniv1.#Image
+		replicas: *3 | int
+		enabled:  *true | bool
+		nodeSelector: {
+			"app.kubernetes.io/zone": *"a" | string
+			disktype:                 *"ssd" | string
+			...
+		}
+		args: [...string]
+		extra: [...]
+		name: string
+	}
+}`, string(b))
+}